@@ -0,0 +1,77 @@
+package cobs
+
+import "sort"
+
+// RecommendForbiddenBytes returns the bytes to reserve on a link that needs
+// more than one forbidden byte. The result starts with the deduplicated
+// required bytes in their given order, followed by every other byte value
+// that is least frequent in data, in ascending order of value. Reserving a
+// byte costs stuffing overhead for every occurrence, so bytes absent from
+// data are free to reserve and are picked first. Empty data gives no
+// frequencies to go by, only the required bytes are returned then. Use
+// RecommendForbiddenBytesN to bound the number of additional bytes.
+func RecommendForbiddenBytes(data []byte, required []byte) []byte {
+	res, candidates, hist := rankForbiddenBytes(data, required)
+
+	n := 0
+	for n < len(candidates) && hist[candidates[n]] == hist[candidates[0]] {
+		n++
+	}
+
+	return append(res, candidates[:n]...)
+}
+
+// RecommendForbiddenBytesN is like RecommendForbiddenBytes, but returns the n
+// least frequent other byte values in data after the required bytes, ordered
+// by frequency and then by value. Fewer are returned if data is empty, n is
+// not positive or fewer values are left.
+func RecommendForbiddenBytesN(data []byte, required []byte, n int) []byte {
+	res, candidates, _ := rankForbiddenBytes(data, required)
+
+	if n < 0 {
+		n = 0
+	}
+	if n > len(candidates) {
+		n = len(candidates)
+	}
+
+	return append(res, candidates[:n]...)
+}
+
+// rankForbiddenBytes returns the deduplicated required bytes, the other byte
+// values ordered by frequency in data and then by value, and the histogram of
+// data. There are no candidates for empty data.
+func rankForbiddenBytes(data []byte, required []byte) ([]byte, []byte, *[256]int) {
+	var hist [256]int
+	var reserved [256]bool
+
+	for _, c := range data {
+		hist[c]++
+	}
+
+	res := make([]byte, 0, len(required))
+	for _, c := range required {
+		if !reserved[c] {
+			reserved[c] = true
+			res = append(res, c)
+		}
+	}
+
+	if len(data) == 0 {
+		return res, nil, &hist
+	}
+
+	candidates := make([]byte, 0, 256-len(res))
+	for c := range hist {
+		if !reserved[c] {
+			candidates = append(candidates, byte(c))
+		}
+	}
+
+	// Candidates are in order of value, which ties keep
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return hist[candidates[i]] < hist[candidates[j]]
+	})
+
+	return res, candidates, &hist
+}
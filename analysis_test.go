@@ -0,0 +1,120 @@
+package cobs
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRecommendForbiddenBytes(t *testing.T) {
+	var data []byte
+	for i := 0; i < 256; i++ {
+		// 0x7e is absent, 0x42 and 0x00 are rare
+		switch byte(i) {
+		case 0x7e:
+		case 0x42, 0x00:
+			data = append(data, byte(i))
+		default:
+			data = append(data, byte(i), byte(i))
+		}
+	}
+
+	got := RecommendForbiddenBytes(data, []byte{0x00, 0x00})
+	want := []byte{0x00, 0x7e}
+	if !bytes.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	data = append(data, 0x7e, 0x7e)
+	got = RecommendForbiddenBytes(data, []byte{0x00})
+	want = []byte{0x00, 0x42}
+	if !bytes.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// All absent values tie, in order of value after the required ones
+	got = RecommendForbiddenBytes([]byte{0x01, 0x00, 0x00}, []byte{0x02})
+	want = []byte{0x02}
+	for i := 3; i < 256; i++ {
+		want = append(want, byte(i))
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	got = RecommendForbiddenBytes(nil, []byte{0x0d, 0x0a})
+	want = []byte{0x0d, 0x0a}
+	if !bytes.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestRecommendForbiddenBytesN(t *testing.T) {
+	// Every value twice, except two rare ones
+	var common []byte
+	for i := 0; i < 256; i++ {
+		common = append(common, byte(i))
+		if i != 0x20 && i != 0x80 {
+			common = append(common, byte(i))
+		}
+	}
+
+	// All values, the absent ones by value and the present one last
+	all := []byte{0x00}
+	for i := 1; i < 256; i++ {
+		if i != 'a' {
+			all = append(all, byte(i))
+		}
+	}
+	all = append(all, 'a')
+
+	testCases := []struct {
+		name     string
+		data     []byte
+		required []byte
+		n        int
+		want     []byte
+	}{
+		{
+			name:     "Empty data",
+			required: []byte{0x00},
+			n:        3,
+			want:     []byte{0x00},
+		},
+		{
+			name:     "Ties ordered by value",
+			data:     []byte("aab"),
+			required: []byte{0x00},
+			n:        3,
+			want:     []byte{0x00, 0x01, 0x02, 0x03},
+		},
+		{
+			name:     "Rare before frequent",
+			data:     common,
+			required: []byte{0x00},
+			n:        2,
+			want:     []byte{0x00, 0x20, 0x80},
+		},
+		{
+			name:     "Capped at all values",
+			data:     []byte("a"),
+			required: []byte{0x00},
+			n:        1000,
+			want:     all,
+		},
+		{
+			name:     "None requested",
+			data:     []byte("a"),
+			required: []byte{0x00, 0x0d},
+			want:     []byte{0x00, 0x0d},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := RecommendForbiddenBytesN(tc.data, tc.required, tc.n)
+			if !bytes.Equal(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
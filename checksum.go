@@ -0,0 +1,118 @@
+package cobs
+
+import (
+	"errors"
+	"hash/crc32"
+)
+
+// ErrChecksumMismatch means that the checksum of a decoded frame is invalid.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// A ChecksumKind selects the checksum appended to the payload of a frame.
+type ChecksumKind int
+
+const (
+	ChecksumNone ChecksumKind = iota // no checksum
+	CRC8                             // CRC-8, polynomial 0x07, initial value 0x00
+	CRC16CCITT                       // CRC-16/CCITT-FALSE, polynomial 0x1021, initial value 0xffff
+	CRC32                            // CRC-32/IEEE, as hash/crc32.ChecksumIEEE
+)
+
+var (
+	crc8Table  [256]uint8
+	crc16Table [256]uint16
+)
+
+func init() {
+	for i := range crc8Table {
+		crc := uint8(i)
+		for j := 0; j < 8; j++ {
+			if crc&0x80 != 0 {
+				crc = crc<<1 ^ 0x07
+			} else {
+				crc <<= 1
+			}
+		}
+		crc8Table[i] = crc
+	}
+
+	for i := range crc16Table {
+		crc := uint16(i) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+		crc16Table[i] = crc
+	}
+}
+
+// size returns the number of checksum bytes.
+func (k ChecksumKind) size() int {
+	switch k {
+	case CRC8:
+		return 1
+	case CRC16CCITT:
+		return 2
+	case CRC32:
+		return 4
+	default:
+		return 0
+	}
+}
+
+// A checksum is computed over the payload of a frame.
+type checksum struct {
+	kind ChecksumKind
+	crc  uint32
+}
+
+func newChecksum(kind ChecksumKind) *checksum {
+	if kind.size() == 0 {
+		return nil
+	}
+
+	c := &checksum{kind: kind}
+	c.reset()
+
+	return c
+}
+
+func (c *checksum) reset() {
+	switch c.kind {
+	case CRC16CCITT:
+		c.crc = 0xffff
+	case CRC32:
+		c.crc = 0xffffffff
+	default:
+		c.crc = 0
+	}
+}
+
+func (c *checksum) update(b byte) {
+	switch c.kind {
+	case CRC8:
+		c.crc = uint32(crc8Table[uint8(c.crc)^b])
+	case CRC16CCITT:
+		crc := uint16(c.crc)
+		c.crc = uint32(crc<<8 ^ crc16Table[uint8(crc>>8)^b])
+	case CRC32:
+		c.crc = crc32.IEEETable[uint8(c.crc)^b] ^ c.crc>>8
+	}
+}
+
+// sum appends the checksum in big-endian byte order to b.
+func (c *checksum) sum(b []byte) []byte {
+	crc := c.crc
+	if c.kind == CRC32 {
+		crc = ^crc
+	}
+
+	for i := c.kind.size() - 1; i >= 0; i-- {
+		b = append(b, byte(crc>>(8*i)))
+	}
+
+	return b
+}
@@ -0,0 +1,111 @@
+package cobs
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestChecksumCheck(t *testing.T) {
+	testCases := []struct {
+		kind ChecksumKind
+		want []byte
+	}{
+		{CRC8, []byte{0xf4}},
+		{CRC16CCITT, []byte{0x29, 0xb1}},
+		{CRC32, []byte{0xcb, 0xf4, 0x39, 0x26}},
+	}
+
+	for _, tc := range testCases {
+		c := newChecksum(tc.kind)
+		for _, b := range []byte("123456789") {
+			c.update(b)
+		}
+		if got := c.sum(nil); !bytes.Equal(got, tc.want) {
+			t.Errorf("kind %d got %x, want %x", tc.kind, got, tc.want)
+		}
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	for _, kind := range []ChecksumKind{CRC8, CRC16CCITT, CRC32} {
+		for _, tc := range testCases {
+			enc, err := Encode(tc.dec, WithChecksum(kind))
+			if err != nil {
+				t.Fatalf("%s: encode error: %v", tc.name, err)
+			}
+			if i := bytes.IndexByte(enc, Delimiter); i != -1 {
+				t.Errorf("%s: encode %v has delimiter at %d", tc.name, enc, i)
+			}
+
+			dec, err := Decode(enc, WithChecksum(kind))
+			if err != nil {
+				t.Fatalf("%s: decode error: %v", tc.name, err)
+			}
+			if !bytes.Equal(dec, tc.dec) {
+				t.Errorf("%s: got %v, want %v", tc.name, dec, tc.dec)
+			}
+
+			// Flip a bit of the payload and checksum, keeping the frame valid
+			raw, err := Decode(enc)
+			if err != nil {
+				t.Fatalf("%s: decode error: %v", tc.name, err)
+			}
+			raw[0] ^= 0x01
+			enc, err = Encode(raw)
+			if err != nil {
+				t.Fatalf("%s: encode error: %v", tc.name, err)
+			}
+			if _, err := Decode(enc, WithChecksum(kind)); !errors.Is(err, ErrChecksumMismatch) {
+				t.Errorf("%s: got %v, want %v", tc.name, err, ErrChecksumMismatch)
+			}
+		}
+	}
+}
+
+func TestChecksumEmptyFrame(t *testing.T) {
+	enc, err := Encode([]byte("a"), WithChecksum(CRC16CCITT))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := append([]byte{Delimiter}, enc...)
+	data = append(data, Delimiter)
+
+	keepAlives := 0
+	keepAlive := WithKeepAlive(func() error {
+		keepAlives++
+
+		return nil
+	})
+
+	testCases := []struct {
+		name   string
+		opts   []Option
+		frames []string
+	}{
+		{name: "Lone delimiter", frames: []string{"", "a"}},
+		{name: "Skip empty frames", opts: []Option{WithSkipEmptyFrames(true)}, frames: []string{"a"}},
+		{name: "Keep-alive", opts: []Option{keepAlive}, frames: []string{"a"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := DecodeFrames(data, append(tc.opts, WithChecksum(CRC16CCITT))...)
+			if err != nil {
+				t.Fatalf("decode frames error: %v", err)
+			}
+			if len(got) != len(tc.frames) {
+				t.Fatalf("got %q, want %q", got, tc.frames)
+			}
+			for i := range got {
+				if string(got[i]) != tc.frames[i] {
+					t.Errorf("frame %d: got %q, want %q", i, got[i], tc.frames[i])
+				}
+			}
+		})
+	}
+
+	if keepAlives != 1 {
+		t.Errorf("got %d keep-alives, want 1", keepAlives)
+	}
+}
@@ -0,0 +1,51 @@
+package cobs
+
+import (
+	"sync"
+	"time"
+)
+
+// A Client exchanges requests and responses as frames over a FrameConn, for
+// command and response protocols. Concurrent calls of RoundTrip are
+// serialized, so every response is received by the caller of its request.
+type Client struct {
+	fc      *FrameConn
+	timeout time.Duration
+	mu      sync.Mutex
+}
+
+// NewClient returns a Client sending requests over fc, which waits at most
+// timeout for a request to be written and its response to be received. A
+// timeout <= 0 means waiting without limit.
+func NewClient(fc *FrameConn, timeout time.Duration) *Client {
+	return &Client{fc: fc, timeout: timeout}
+}
+
+// RoundTrip writes req as a frame and returns the next frame received as its
+// response. If the timeout expires, the error of the deadline is returned,
+// which wraps os.ErrDeadlineExceeded. A response arriving later is received
+// by the next call of RoundTrip, so the protocol has to match responses to
+// requests, or the connection has to be closed.
+func (c *Client) RoundTrip(req []byte) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var deadline time.Time
+	if c.timeout > 0 {
+		deadline = time.Now().Add(c.timeout)
+	}
+	if err := c.fc.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	if err := c.fc.WriteFrame(req); err != nil {
+		return nil, err
+	}
+
+	return c.fc.ReadFrame()
+}
+
+// Close closes the connection.
+func (c *Client) Close() error {
+	return c.fc.Close()
+}
@@ -0,0 +1,67 @@
+package cobs
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClient(t *testing.T) {
+	c1, c2 := net.Pipe()
+	client := NewClient(NewFrameConn(c1), time.Second)
+	server := NewFrameConn(c2)
+	defer client.Close()
+	defer server.Close()
+
+	// The server echoes every request in upper case
+	go func() {
+		for {
+			req, err := server.ReadFrame()
+			if err != nil {
+				return
+			}
+			if err := server.WriteFrame(bytes.ToUpper(req)); err != nil {
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for _, req := range []string{"a", "bc", "def", "ghij"} {
+		wg.Add(1)
+		go func(req string) {
+			defer wg.Done()
+
+			resp, err := client.RoundTrip([]byte(req))
+			if err != nil {
+				t.Errorf("round trip %q: %v", req, err)
+				return
+			}
+			if want := bytes.ToUpper([]byte(req)); !bytes.Equal(resp, want) {
+				t.Errorf("got %q, want %q", resp, want)
+			}
+		}(req)
+	}
+	wg.Wait()
+}
+
+func TestClientTimeout(t *testing.T) {
+	c1, c2 := net.Pipe()
+	client := NewClient(NewFrameConn(c1), 50*time.Millisecond)
+	server := NewFrameConn(c2)
+	defer client.Close()
+	defer server.Close()
+
+	// The server receives the request, but does not respond
+	go func() {
+		_, _ = server.ReadFrame()
+	}()
+
+	if _, err := client.RoundTrip([]byte("ping")); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("got %v, want %v", err, os.ErrDeadlineExceeded)
+	}
+}
@@ -3,24 +3,162 @@ Decode reads from standard input, and writes the decoded data to standard output
 
 Usage:
 
-    decode
+	decode [flags]
 
-When decode reads a zero delimiter it will stop processing data. If malformed encoded data
-is passed the program will panic.
+The flags are:
+
+	-i, -input file
+	    Read from file instead of standard input.
+	-o, -output file
+	    Write to file instead of standard output.
+	-all
+	    Decode all frames instead of stopping after the first.
+	-dump
+	    Write each frame as hex dump instead of raw data.
+
+When decode reads a zero delimiter it will stop processing data, unless -all is given.
+If malformed encoded data is passed the program reports the error and exits with a
+non-zero status:
+
+	1  reading or writing data failed
+	2  a delimiter was found in the middle of a frame
+	3  the input ended in the middle of a frame
 */
 package main
 
 import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
 	"io"
 	"os"
 
 	"github.com/pdgendt/cobs"
 )
 
+const (
+	exitOK = iota
+	exitError
+	exitUnexpectedEOD
+	exitIncompleteFrame
+)
+
 func main() {
-	dec := cobs.NewDecoder(os.Stdout)
+	var input, output string
+	var all, dump bool
+
+	flag.StringVar(&input, "i", "", "Read from `file` instead of stdin")
+	flag.StringVar(&input, "input", "", "Read from `file` instead of stdin")
+	flag.StringVar(&output, "o", "", "Write to `file` instead of stdout")
+	flag.StringVar(&output, "output", "", "Write to `file` instead of stdout")
+	flag.BoolVar(&all, "all", false, "Decode all frames")
+	flag.BoolVar(&dump, "dump", false, "Write frames as hex dump")
+	flag.Parse()
+
+	code, msg := status(run(input, output, all, dump))
+	if code != exitOK {
+		fmt.Fprintf(os.Stderr, "decode: %s\n", msg)
+	}
+
+	os.Exit(code)
+}
+
+func run(input, output string, all, dump bool) (err error) {
+	in := os.Stdin
+	if input != "" {
+		if in, err = os.Open(input); err != nil {
+			return err
+		}
+		defer in.Close()
+	}
+
+	out := os.Stdout
+	if output != "" {
+		if out, err = os.Create(output); err != nil {
+			return err
+		}
+		defer func() {
+			if cerr := out.Close(); err == nil {
+				err = cerr
+			}
+		}()
+	}
+
+	return decode(out, in, all, dump)
+}
+
+// decode decodes a single frame from r to w, or all frames if all is set.
+// With dump the frames are written as hex dump.
+func decode(w io.Writer, r io.Reader, all, dump bool) error {
+	if all && !dump {
+		_, err := cobs.CopyFramesContext(context.Background(), w, r)
+
+		return err
+	}
+
+	var opts []cobs.Option
+	if dump {
+		opts = append(opts, cobs.WithFrameHandler(dumper(w, all)))
+	}
+
+	dec, err := cobs.NewDecoder(w, opts...)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(dec, r)
+	if err == nil {
+		// The input ended without a delimiter
+		err = dec.Close()
+	}
+
+	// The first frame ended, possibly reported by the dumper
+	if errors.Is(err, cobs.EOD) {
+		return nil
+	}
+
+	return err
+}
+
+// dumper returns a frame handler writing a hex dump of each frame to w.
+// Unless all is set, the handler returns EOD after the first frame.
+func dumper(w io.Writer, all bool) func([]byte) error {
+	n := 0
+
+	return func(frame []byte) error {
+		if _, err := fmt.Fprintf(w, "frame %d, %d bytes:\n", n, len(frame)); err != nil {
+			return err
+		}
+		n++
+
+		d := hex.Dumper(w)
+		if _, err := d.Write(frame); err != nil {
+			return err
+		}
+		if err := d.Close(); err != nil {
+			return err
+		}
+
+		if !all {
+			return cobs.EOD
+		}
+
+		return nil
+	}
+}
 
-	if _, err := io.Copy(dec, os.Stdin); err != nil && err != cobs.EOD {
-		panic(err)
+// status returns the exit code and message for err.
+func status(err error) (int, string) {
+	switch {
+	case err == nil:
+		return exitOK, ""
+	case errors.Is(err, cobs.ErrUnexpectedEOD):
+		return exitUnexpectedEOD, "unexpected end of frame"
+	case errors.Is(err, cobs.ErrIncompleteFrame):
+		return exitIncompleteFrame, "incomplete frame"
+	default:
+		return exitError, err.Error()
 	}
 }
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+type failWriter struct{}
+
+func (failWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestStatus(t *testing.T) {
+	testCases := []struct {
+		name string
+		w    io.Writer
+		in   []byte
+		code int
+	}{
+		{
+			name: "Complete frame",
+			w:    io.Discard,
+			in:   []byte("\x06Hello\x00"),
+			code: exitOK,
+		},
+		{
+			name: "No delimiter",
+			w:    io.Discard,
+			in:   []byte("\x06Hello"),
+			code: exitOK,
+		},
+		{
+			name: "Unexpected delimiter",
+			w:    io.Discard,
+			in:   []byte("\x06Hel\x00"),
+			code: exitUnexpectedEOD,
+		},
+		{
+			name: "Incomplete frame",
+			w:    io.Discard,
+			in:   []byte("\x06Hel"),
+			code: exitIncompleteFrame,
+		},
+		{
+			name: "Write error",
+			w:    failWriter{},
+			in:   []byte("\x06Hello\x00"),
+			code: exitError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			code, msg := status(decode(tc.w, bytes.NewReader(tc.in), false, false))
+			if code != tc.code {
+				t.Errorf("got exit code %d (%q), want %d", code, msg, tc.code)
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	in := []byte("\x06Hello\x00\x03\x01\xff\x00")
+
+	testCases := []struct {
+		name      string
+		all, dump bool
+		out       string
+	}{
+		{
+			name: "First frame",
+			out:  "Hello",
+		},
+		{
+			name: "All frames",
+			all:  true,
+			out:  "Hello\x01\xff",
+		},
+		{
+			name: "Dump first frame",
+			dump: true,
+			out: "frame 0, 5 bytes:\n" +
+				"00000000  48 65 6c 6c 6f                                    |Hello|\n",
+		},
+		{
+			name: "Dump all frames",
+			all:  true,
+			dump: true,
+			out: "frame 0, 5 bytes:\n" +
+				"00000000  48 65 6c 6c 6f                                    |Hello|\n" +
+				"frame 1, 2 bytes:\n" +
+				"00000000  01 ff                                             |..|\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := decode(&out, bytes.NewReader(in), tc.all, tc.dump); err != nil {
+				t.Fatalf("decode error: %v", err)
+			}
+			if got := out.String(); got != tc.out {
+				t.Errorf("got %q, want %q", got, tc.out)
+			}
+		})
+	}
+}
+
+func TestDumpNoDelimiter(t *testing.T) {
+	want := "frame 0, 2 bytes:\n" +
+		"00000000  68 69                                             |hi|\n"
+
+	for _, all := range []bool{false, true} {
+		var out bytes.Buffer
+		code, msg := status(decode(&out, bytes.NewReader([]byte("\x03hi")), all, true))
+		if code != exitOK {
+			t.Errorf("all %v: got exit code %d (%q), want %d", all, code, msg, exitOK)
+		}
+		if got := out.String(); got != want {
+			t.Errorf("all %v: got %q, want %q", all, got, want)
+		}
+	}
+}
@@ -3,17 +3,24 @@ Encode reads from standard input, and writes the encoded data to standard output
 
 Usage:
 
-    encode [flags]
+	encode [flags]
 
 The flags are:
 
-    -del
-        Append the encoded data with a (zero) delimiter.
+	-del
+	    Append the encoded data with a (zero) delimiter.
+	-i, -input file
+	    Read from file instead of standard input.
+	-o, -output file
+	    Write to file instead of standard output.
+	-stats
+	    Report the number of bytes and the overhead to standard error.
 */
 package main
 
 import (
 	"flag"
+	"fmt"
 	"io"
 	"os"
 
@@ -21,20 +28,100 @@ import (
 )
 
 func main() {
+	var input, output string
+
 	delimiter := flag.Bool("del", false, "Append a delimiter")
+	stats := flag.Bool("stats", false, "Report the overhead to stderr")
+	flag.StringVar(&input, "i", "", "Read from `file` instead of stdin")
+	flag.StringVar(&input, "input", "", "Read from `file` instead of stdin")
+	flag.StringVar(&output, "o", "", "Write to `file` instead of stdout")
+	flag.StringVar(&output, "output", "", "Write to `file` instead of stdout")
 	flag.Parse()
 
-	enc := cobs.NewEncoder(os.Stdout)
+	if err := run(input, output, *delimiter, *stats); err != nil {
+		fmt.Fprintf(os.Stderr, "encode: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(input, output string, delimiter, stats bool) (err error) {
+	in := os.Stdin
+	if input != "" {
+		if in, err = os.Open(input); err != nil {
+			return err
+		}
+		defer in.Close()
+	}
+
+	out := os.Stdout
+	if output != "" {
+		if out, err = os.Create(output); err != nil {
+			return err
+		}
+		defer func() {
+			if cerr := out.Close(); err == nil {
+				err = cerr
+			}
+		}()
+	}
 
-	if _, err := io.Copy(enc, os.Stdin); err != nil {
-		panic(err)
+	nin, nout, err := encode(out, in, delimiter)
+	if err != nil {
+		return err
+	}
+
+	if stats {
+		fmt.Fprintln(os.Stderr, overhead(nin, nout))
+	}
+
+	return nil
+}
+
+// countWriter counts the bytes written to w.
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+
+	return n, err
+}
+
+// encode encodes r to w and returns the number of bytes read and written.
+func encode(w io.Writer, r io.Reader, delimiter bool) (int64, int64, error) {
+	cw := &countWriter{w: w}
+	enc, err := cobs.NewEncoder(cw)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	in, err := io.Copy(enc, r)
+	if err != nil {
+		return in, cw.n, err
 	}
 
 	if err := enc.Close(); err != nil {
-		panic(err)
+		return in, cw.n, err
+	}
+
+	if delimiter {
+		if _, err := cw.Write([]byte{cobs.Delimiter}); err != nil {
+			return in, cw.n, err
+		}
 	}
 
-	if *delimiter {
-		os.Stdout.Write([]byte{cobs.Delimiter})
+	return in, cw.n, nil
+}
+
+// overhead formats the overhead of encoding in bytes to out bytes.
+func overhead(in, out int64) string {
+	pct := 0.0
+	if in > 0 {
+		pct = float64(out-in) * 100 / float64(in)
 	}
+
+	return fmt.Sprintf("in=%d out=%d overhead=%d (%.1f%%)", in, out, out-in, pct)
 }
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncodeStats(t *testing.T) {
+	testCases := []struct {
+		name      string
+		in        []byte
+		delimiter bool
+		stats     string
+	}{
+		{
+			name:  "Empty",
+			in:    []byte{},
+			stats: "in=0 out=1 overhead=1 (0.0%)",
+		},
+		{
+			name:  "Data",
+			in:    bytes.Repeat([]byte("a"), 1000),
+			stats: "in=1000 out=1004 overhead=4 (0.4%)",
+		},
+		{
+			name:      "Delimiter",
+			in:        []byte("Hello"),
+			delimiter: true,
+			stats:     "in=5 out=7 overhead=2 (40.0%)",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			in, n, err := encode(&out, bytes.NewReader(tc.in), tc.delimiter)
+			if err != nil {
+				t.Fatalf("encode error: %v", err)
+			}
+			if n != int64(out.Len()) {
+				t.Errorf("counted %d bytes, wrote %d", n, out.Len())
+			}
+			if got := overhead(in, n); got != tc.stats {
+				t.Errorf("got %q, want %q", got, tc.stats)
+			}
+		})
+	}
+}
@@ -3,9 +3,15 @@
 package cobs
 
 import (
+	"bufio"
 	"bytes"
+	"encoding/binary"
 	"errors"
+	"fmt"
 	"io"
+	"strings"
+	"sync"
+	"time"
 )
 
 const (
@@ -13,41 +19,350 @@ const (
 )
 
 // EOD is the error returned when decoding and a delimiter was written.
-// Functions return EOD to signal a graceful end of a frame.
+// Functions return EOD to signal a graceful end of a frame. See WithEODasEOF
+// to return io.EOF instead.
 var EOD = errors.New("EOD")
 
 // ErrUnexpectedEOD means that a delimiter was encountered in a malformed frame.
 var ErrUnexpectedEOD = errors.New("unexpected EOD")
 
-// An Encoder implements the io.Writer and io.ByteWriter interfaces. Data
-// written will we be encoded into groups and forwarded.
+// ErrIncompleteFrame means that a frame ended in the middle of a group.
+var ErrIncompleteFrame = errors.New("incomplete frame")
+
+// ErrFrameTooLarge means that a frame decoded to more bytes than allowed.
+var ErrFrameTooLarge = errors.New("frame too large")
+
+// ErrTooManyGroups means that a frame consisted of more groups than allowed.
+var ErrTooManyGroups = errors.New("too many groups")
+
+// A FrameError records a decoding error and the offset of the offending
+// byte, relative to the start of the current frame. Frame is the number of
+// frames decoded before, which does not count frames dropped by WithResync.
+// InputOffset is the offset of the offending byte in all input, which locates
+// the error in a long stream.
+type FrameError struct {
+	Err         error
+	Offset      int
+	Frame       uint64
+	InputOffset int64
+}
+
+func (e *FrameError) Error() string {
+	return fmt.Sprintf("%v at offset %d of frame %d (input offset %d)", e.Err, e.Offset, e.Frame, e.InputOffset)
+}
+
+func (e *FrameError) Unwrap() error {
+	return e.Err
+}
+
+// An Encoder implements the io.Writer, io.ByteWriter and io.StringWriter
+// interfaces. Data written will we be encoded into groups and forwarded.
 type Encoder struct {
-	w   io.Writer
-	buf []byte
+	w         io.Writer
+	buf       []byte
+	reduced   bool
+	delimiter bool
+	sentinel  byte
+	native    bool
+	delim     []byte
+	delimBuf  [1]byte
+	hist      *Histogram
+	metrics   Metrics
+	produced  uint64
+	frameIn   uint64
+	frameOut  uint64
+	split     byte
+	sum       *checksum
+	pace      *pacer
+	prefix    bool
+	payload   []byte
+	size      int
+	minLen    int
+	pad       byte
+	zpe       bool
+	zero      bool
+	zre       bool
+	run       int
+	seq       bool
+	seqNum    byte
+	seqDone   bool
+	maxCode   byte
+	second    bool
+	forbid    byte
+	closeW    bool
+	lead      bool
+	leading   bool
+	out       []byte
+	stats     Stats
+	cfg       Config
+	err       error
 }
 
-// A Decoder implements the io.Writer and io.ByteWriter interfaces. Data
-// written will we be decoded and forwarded byte per byte.
+// A Decoder implements the io.WriteCloser and io.ByteWriter interfaces. Data
+// written will we be decoded and forwarded. Decoded data is collected and
+// forwarded once per call of Write, or when a frame is complete.
 type Decoder struct {
 	w         io.Writer
+	out       io.Writer
+	route     func(byte) io.Writer
+	handler   func([]byte) error
+	frame     []byte
 	code      byte
+	prev      byte
 	codeIndex byte
+	offset    int
+	size      int
+	maxSize   int
+	groups    int
+	maxGroups int
+	discard   bool
+	reduced   bool
+	sentinel  byte
+	native    bool
+	delim     []byte
+	delimRest int
+	inserted  byte
+	newline   bool
+	sep       []byte
+	sum       *checksum
+	tail      []byte
+	vars      *decoderVars
+	hist      *Histogram
+	metrics   Metrics
+	pend      []byte
+	marks     []mark
+	pos       int
+	frames    uint64
+	prefix    bool
+	seq       bool
+	seqNum    byte
+	seqDone   bool
+	seqSeen   bool
+	lastSeq   byte
+	lenDone   bool
+	lenShift  uint
+	length    uint64
+	zpe       bool
+	zre       bool
+	second    bool
+	forbid    byte
+	full      byte
+	skipEmpty bool
+	keepAlive func() error
+	closeW    bool
+	eod       error
+	strict    bool
+	resync    bool
+	timeout   time.Duration
+	began     time.Time
+	clock     clock
+	codeAt    int
+	bw        *bufio.Writer
+	start     int64
+	stats     Stats
+	cfg       Config
+	err       error
+}
+
+// A mark attributes pending output from off onwards to input from pos.
+type mark struct {
+	off int
+	pos int
+}
+
+// A writeError is an error of w, n is the number of consumed input bytes.
+type writeError struct {
+	n   int
+	err error
+}
+
+func (e *writeError) Error() string {
+	return e.err.Error()
 }
 
-// NewEncoder returns an Encoder that writes encoded data to w.
-func NewEncoder(w io.Writer) *Encoder {
+// writeFull writes p to w, retrying after a short write that is not reported
+// as error. A write that makes no progress results in io.ErrShortWrite.
+func writeFull(w io.Writer, p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		m, err := w.Write(p[n:])
+		n += m
+
+		if err != nil {
+			return n, err
+		}
+		if m == 0 {
+			return n, io.ErrShortWrite
+		}
+	}
+
+	return n, nil
+}
+
+// NewEncoder returns an Encoder that writes encoded data to w. If an option
+// is invalid, or options can not be used together, an error wrapping
+// ErrInvalidOption or ErrIncompatibleOptions is returned instead.
+func NewEncoder(w io.Writer, opts ...Option) (*Encoder, error) {
+	e := newEncoder(w, opts...)
+	if e.err != nil {
+		return nil, e.err
+	}
+
+	return e, nil
+}
+
+// newEncoder returns an Encoder like NewEncoder, which returns an error of
+// the options from every call instead.
+func newEncoder(w io.Writer, opts ...Option) *Encoder {
 	e := new(Encoder)
+	e.init(w, newConfig(opts))
+
+	return e
+}
 
+// init sets up e to write to w with the settings of c, reusing its buffers.
+func (e *Encoder) init(w io.Writer, c Config) {
 	e.w = w
+	e.cfg = c
+	e.stats = Stats{}
+	e.reduced = c.Reduced
+	e.delimiter = c.DelimiterOnClose
+	e.sentinel = c.Sentinel
+	e.delimBuf[0] = c.Sentinel
+	e.delim = e.delimBuf[:]
+	if len(c.delimSeq) > 0 {
+		e.delim = c.delimSeq
+	}
+	e.native = c.SentinelMode == SentinelNative
+	e.hist = c.histogram
+	e.metrics = c.metrics
+	e.produced = 0
+	e.frameIn = 0
+	e.frameOut = 0
+	e.split = c.InsertedByte
+	if e.native {
+		e.split = c.Sentinel
+	}
+	e.sum = newChecksum(c.Checksum)
+	e.pace = nil
+	if c.RateLimit > 0 {
+		e.pace = &pacer{rate: c.RateLimit, clock: c.clock}
+	}
+	e.prefix = c.LengthPrefix
+	e.payload = e.payload[:0]
+	e.size = 0
+	e.minLen = c.MinPayload
+	e.pad = c.Pad
+	e.zpe = c.ZPE
+	e.zero = false
+	e.zre = c.ZRE
+	e.run = 0
+	e.seq = c.Sequence
+	e.seqNum = 0
+	e.seqDone = false
+	e.maxCode = maxCode(c.ZPE)
+	if c.MaxGroupSize > 0 {
+		e.maxCode = c.fullCode()
+	}
+	if c.ZRE {
+		e.maxCode = zreMaxCode
+	}
+	e.second = c.SecondForbidden
+	e.closeW = c.CloseUnderlying
+	e.leading = c.PrefixDelimiter
+	e.out = e.out[:0]
+	if cap(e.out) != c.WriteBufferSize {
+		e.out = nil
+		if c.WriteBufferSize > 0 {
+			e.out = make([]byte, 0, c.WriteBufferSize)
+		}
+	}
+	e.lead = c.PrefixDelimiter
+	e.forbid = c.Forbidden ^ c.Sentinel
+	e.err = c.check()
 	// Create a buffer with maximum capacity for a group
-	e.buf = make([]byte, 1, 255)
+	if e.buf == nil {
+		e.buf = make([]byte, 1, 255)
+	}
+	e.buf = e.buf[:1]
 	e.buf[0] = 1
+}
 
-	return e
+// Reset discards the state of e, including a partial frame and buffered
+// data, and makes it write to w with the same options as before. This allows
+// reusing an Encoder instead of allocating a new one.
+func (e *Encoder) Reset(w io.Writer) {
+	e.init(w, e.cfg)
+}
+
+// write forwards p to w, through the output buffer if enabled.
+func (e *Encoder) write(p []byte) error {
+	e.produced += uint64(len(p))
+
+	if cap(e.out) == 0 {
+		return e.writeOut(p)
+	}
+
+	if len(e.out)+len(p) > cap(e.out) {
+		if err := e.flushOut(); err != nil {
+			return err
+		}
+	}
+
+	if len(p) > cap(e.out) {
+		return e.writeOut(p)
+	}
+	e.out = append(e.out, p...)
+
+	return nil
+}
+
+// flushOut writes the output buffer to w.
+func (e *Encoder) flushOut() error {
+	if len(e.out) == 0 {
+		return nil
+	}
+
+	err := e.writeOut(e.out)
+	e.out = e.out[:0]
+
+	return err
+}
+
+// writeOut writes p to w.
+func (e *Encoder) writeOut(p []byte) error {
+	if e.pace != nil {
+		e.pace.wait(len(p))
+	}
+
+	n, err := writeFull(e.w, p)
+	e.stats.BytesOut += uint64(n)
+	if err != nil {
+		e.stats.Errors++
+		if e.metrics != nil {
+			e.metrics.Error(err)
+		}
+	}
+
+	return err
 }
 
 func (e *Encoder) finish() error {
-	if _, err := e.w.Write(e.buf); err != nil {
+	// The first group of a frame might be preceded by a delimiter
+	if e.lead {
+		if err := e.write(e.delim); err != nil {
+			return err
+		}
+		e.lead = false
+	}
+
+	if e.native {
+		e.buf[0] = swapSentinel(e.buf[0], e.sentinel)
+	} else if e.sentinel != Delimiter {
+		xorBytes(e.buf, e.sentinel)
+	}
+
+	if err := e.write(e.buf); err != nil {
 		return err
 	}
 
@@ -61,14 +376,74 @@ func (e *Encoder) finish() error {
 // WriteByte encodes a single byte c. If a group is finished
 // it is written to w.
 func (e *Encoder) WriteByte(c byte) error {
+	if e.err != nil {
+		return e.err
+	}
+	e.stats.BytesIn++
+
+	if err := e.putSequence(); err != nil {
+		return err
+	}
+
+	// The payload is needed for its length prefix
+	if e.prefix {
+		e.payload = append(e.payload, c)
+
+		return nil
+	}
+	e.size++
+
+	return e.put(c)
+}
+
+// put adds c to the checksum and encodes it.
+func (e *Encoder) put(c byte) error {
+	if e.sum != nil {
+		e.sum.update(c)
+	}
+
+	return e.encode(c)
+}
+
+// encode adds c to the current group.
+func (e *Encoder) encode(c byte) error {
+	if e.second {
+		return e.encodeSecond(c)
+	}
+
+	if e.zre {
+		return e.encodeZRE(c)
+	}
+
+	// A zero ended the group, a second one makes a pair
+	if e.zero {
+		e.zero = false
+
+		if c == e.split {
+			e.buf[0] += zpePairCode - 1
+
+			return e.finish()
+		}
+
+		if err := e.finish(); err != nil {
+			return err
+		}
+	}
+
 	// Finish if group is full
-	if e.buf[0] == 0xff {
+	if e.buf[0] == e.maxCode {
 		if err := e.finish(); err != nil {
 			return err
 		}
 	}
 
-	if c == Delimiter {
+	if c == e.split {
+		if e.zpe && int(e.buf[0]) <= zpeMaxPair+1 {
+			e.zero = true
+
+			return nil
+		}
+
 		return e.finish()
 	}
 
@@ -78,104 +453,1163 @@ func (e *Encoder) WriteByte(c byte) error {
 	return nil
 }
 
-// Write will call WriteByte for each byte in p.
+// Write encodes the bytes in p, like calling WriteByte for each byte. Runs
+// of bytes that do not end a group are added to it at once.
 func (e *Encoder) Write(p []byte) (int, error) {
-	for i, c := range p {
-		if err := e.WriteByte(c); err != nil {
+	if e.err != nil {
+		return 0, e.err
+	}
+
+	if len(p) > 0 {
+		if err := e.putSequence(); err != nil {
+			return 0, err
+		}
+	}
+
+	// The payload is needed for its length prefix
+	if e.prefix {
+		e.payload = append(e.payload, p...)
+		e.stats.BytesIn += uint64(len(p))
+
+		return len(p), nil
+	}
+
+	for i := 0; i < len(p); {
+		n, err := e.encodeRun(p[i:])
+		if err == nil && n == 0 {
+			if err = e.WriteByte(p[i]); err == nil {
+				n = 1
+			}
+		}
+
+		if err != nil {
 			return i, err
 		}
+
+		i += n
 	}
 
 	return len(p), nil
 }
 
+// encodeRun adds the bytes at the start of p that fit in the current group
+// and do not end it at once, and returns the number of bytes added. Bytes
+// that need more than a copy are left to encode, in which case 0 is
+// returned.
+func (e *Encoder) encodeRun(p []byte) (int, error) {
+	if e.second || e.zero || e.run > 0 || e.sum != nil {
+		return 0, nil
+	}
+
+	// Finish if group is full
+	if e.buf[0] == e.maxCode {
+		if err := e.finish(); err != nil {
+			return 0, err
+		}
+	}
+
+	run := p
+	if room := int(e.maxCode - e.buf[0]); len(run) > room {
+		run = run[:room]
+	}
+	if j := bytes.IndexByte(run, e.split); j != -1 {
+		run = run[:j]
+	}
+
+	e.buf = append(e.buf, run...)
+	e.buf[0] += byte(len(run))
+	e.size += len(run)
+	e.stats.BytesIn += uint64(len(run))
+
+	return len(run), nil
+}
+
+// WriteString is like Write, but encodes the bytes of s, which avoids
+// converting s to a byte slice.
+func (e *Encoder) WriteString(s string) (int, error) {
+	for i := 0; i < len(s); i++ {
+		if err := e.WriteByte(s[i]); err != nil {
+			return i, err
+		}
+	}
+
+	return len(s), nil
+}
+
+// Buffered returns the number of data bytes held in the current group, which
+// are written once the group is finished. With WithLengthPrefix this includes
+// the payload held until Close.
+func (e *Encoder) Buffered() int {
+	return len(e.buf) - 1 + len(e.payload)
+}
+
+// Flush writes the finished groups held back by WithWriteBufferSize, without
+// ending the frame. The group in progress can not be written: its code tells
+// whether a zero follows, which is only known once the group ends. Buffered
+// reports the data bytes it holds.
+func (e *Encoder) Flush() error {
+	if e.err != nil {
+		return e.err
+	}
+
+	return e.flushOut()
+}
+
 // Close has to be called after writing a full frame and
-// will write the last group.
+// will write the last group, followed by a delimiter if enabled.
 func (e *Encoder) Close() error {
-	return e.finish()
+	if err := e.endFrame(); err != nil {
+		return err
+	}
+
+	if e.delimiter {
+		if err := e.write(e.delim); err != nil {
+			return err
+		}
+	}
+	e.recordFrame()
+
+	if err := e.flushOut(); err != nil {
+		return err
+	}
+
+	if c, ok := e.w.(io.Closer); ok && e.closeW {
+		return c.Close()
+	}
+
+	return nil
+}
+
+// WriteFrame encodes p as a complete frame, like Write followed by Close,
+// and always terminates it with a delimiter. The Encoder is ready for the
+// next frame afterwards. It returns the number of bytes of p consumed.
+func (e *Encoder) WriteFrame(p []byte) (int, error) {
+	n, err := e.Write(p)
+	if err != nil {
+		return n, err
+	}
+
+	if err := e.endFrame(); err != nil {
+		return n, err
+	}
+
+	if err := e.write(e.delim); err != nil {
+		return n, err
+	}
+	e.recordFrame()
+
+	return n, e.flushOut()
+}
+
+// endFrame writes the last group of a frame and resets the frame state.
+func (e *Encoder) endFrame() error {
+	if e.err != nil {
+		return e.err
+	}
+
+	if err := e.putSequence(); err != nil {
+		return err
+	}
+
+	if err := e.closePayload(); err != nil {
+		return err
+	}
+
+	if e.sum != nil {
+		var b [4]byte
+		for _, c := range e.sum.sum(b[:0]) {
+			if err := e.encode(c); err != nil {
+				return err
+			}
+		}
+		e.sum.reset()
+	}
+
+	if e.second {
+		e.closeSecond()
+	}
+
+	// The zero ending the frame extends a run
+	if e.run > 0 {
+		e.buf[0] = zreCode(e.run + 1)
+		e.run = 0
+	}
+
+	// The zero ending the frame completes a pair
+	if e.zero {
+		e.zero = false
+		e.buf[0] += zpePairCode - 1
+	}
+
+	// COBS/R replaces the code with the last byte if it is larger
+	if n := len(e.buf) - 1; e.reduced && n > 0 && e.buf[n] > e.buf[0] {
+		e.buf[0] = e.buf[n]
+		e.buf = e.buf[:n]
+	}
+
+	if err := e.finish(); err != nil {
+		return err
+	}
+	e.lead = e.leading
+	e.seqDone = false
+	e.stats.Frames++
+
+	return nil
+}
+
+// maxPooledSize is the capacity above which buffers are not pooled.
+const maxPooledSize = 64 << 10
+
+var bufPool = sync.Pool{
+	New: func() any {
+		return new(bytes.Buffer)
+	},
+}
+
+// getBuffer returns an empty buffer from the pool.
+func getBuffer() *bytes.Buffer {
+	buf := bufPool.Get().(*bytes.Buffer)
+	buf.Reset()
+
+	return buf
 }
 
-// Encode encodes and returns a byte slice.
-func Encode(data []byte) ([]byte, error) {
-	// Reserve a buffer with overhead room
-	buf := bytes.NewBuffer(make([]byte, 0, len(data) + (len(data) + 253) / 254))
-	e := NewEncoder(buf)
+// putBuffer returns buf to the pool and a copy of its contents.
+func putBuffer(buf *bytes.Buffer) []byte {
+	b := make([]byte, buf.Len())
+	copy(b, buf.Bytes())
+	releaseBuffer(buf)
+
+	return b
+}
+
+// releaseBuffer returns buf to the pool, unless it grew too large.
+func releaseBuffer(buf *bytes.Buffer) {
+	if buf.Cap() <= maxPooledSize {
+		bufPool.Put(buf)
+	}
+}
+
+// Bytes is the constraint of the data Encode and Decode accept, byte slices
+// and strings.
+type Bytes interface {
+	~[]byte | ~string
+}
+
+// Encode encodes data and returns the result as the type of data, either a
+// byte slice or a string. Neither is converted to the other, a nil slice has
+// to be passed as []byte(nil).
+func Encode[T Bytes](data T, opts ...Option) (T, error) {
+	switch p := any(data).(type) {
+	case []byte:
+		enc, err := encodeBytes(p, opts)
+
+		return any(enc).(T), err
+	case string:
+		enc, err := EncodeString(p, opts...)
+
+		return any(enc).(T), err
+	}
+
+	enc, err := encodeBytes([]byte(data), opts)
+
+	return T(enc), err
+}
+
+// encodeBytes encodes data into a new byte slice.
+func encodeBytes(data []byte, opts []Option) ([]byte, error) {
+	buf := getBuffer()
+	e := newEncoder(buf, append(opts[:len(opts):len(opts)], WithRateLimit(0))...)
 
 	if _, err := e.Write(data); err != nil {
-		return buf.Bytes(), err
+		return putBuffer(buf), err
 	}
 
 	err := e.Close()
 
-	return buf.Bytes(), err
+	return putBuffer(buf), err
+}
+
+// NewDecoder returns a Decoder that writes decoded data to w. If an option
+// is invalid, or options can not be used together, an error wrapping
+// ErrInvalidOption or ErrIncompatibleOptions is returned instead.
+func NewDecoder(w io.Writer, opts ...Option) (*Decoder, error) {
+	d := newDecoder(w, opts...)
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	return d, nil
 }
 
-// NewDecoder returns a Decoder that writes decoded data to w.
-func NewDecoder(w io.Writer) *Decoder {
+// newDecoder returns a Decoder like NewDecoder, which returns an error of
+// the options from every call instead.
+func newDecoder(w io.Writer, opts ...Option) *Decoder {
 	d := new(Decoder)
+	d.init(w, newConfig(opts))
+
+	return d
+}
 
+// init sets up d to write to w with the settings of c, reusing its buffers.
+func (d *Decoder) init(w io.Writer, c Config) {
 	d.w = w
-	d.code = 0xff
-	d.codeIndex = 0
+	d.cfg = c
+	d.route = c.typeRouter
+	d.handler = c.frameHandler
+	d.discard = c.DiscardFirstPartial
+	d.skipEmpty = c.SkipEmptyFrames || c.PrefixDelimiter
+	d.keepAlive = c.keepAlive
+	d.closeW = c.CloseUnderlying
+	d.strict = c.Strict && !c.Reduced
+	d.resync = c.Resync
+	d.timeout = c.FrameTimeout
+	d.clock = c.clock
+	d.eod = EOD
+	switch {
+	case c.AutoReset:
+		d.eod = nil
+	case c.EODasEOF:
+		d.eod = io.EOF
+	}
+	d.maxSize = c.MaxFrameSize
+	d.maxGroups = c.MaxGroupsPerFrame
+	d.prefix = c.LengthPrefix
+	d.seq = c.Sequence
+	d.seqSeen = false
+	d.lastSeq = 0
+	d.reduced = c.Reduced
+	d.sentinel = c.Sentinel
+	d.native = c.SentinelMode == SentinelNative
+	d.delim = c.delimSeq
+	d.delimRest = 0
+	d.inserted = c.InsertedByte
+	d.newline = c.AppendNewline
+	d.sep = c.separator
+	d.zpe = c.ZPE
+	d.zre = c.ZRE
+	d.second = c.SecondForbidden
+	d.forbid = c.Forbidden ^ c.Sentinel
+	d.full = maxCode(c.ZPE)
+	if c.MaxGroupSize > 0 {
+		d.full = c.fullCode()
+	}
+	if c.ZRE {
+		d.full = zreMaxCode
+	}
+	if c.SecondForbidden {
+		d.full = secondCode(2*secondMax, d.forbid)
+	}
+	d.err = c.check()
+	d.sum = newChecksum(c.Checksum)
+	d.vars = nil
+	d.hist = c.histogram
+	d.metrics = c.metrics
+	if c.Expvar != "" {
+		d.vars = newDecoderVars(c.Expvar)
+	}
+	d.pend = d.pend[:0]
+	d.marks = d.marks[:0]
+	d.pos = 0
+	d.frames = 0
+	d.stats = Stats{}
+	switch {
+	case c.WriteBufferSize <= 0 || w == nil:
+		d.bw = nil
+	case d.bw != nil && d.bw.Size() == c.WriteBufferSize:
+		d.bw.Reset(w)
+	default:
+		d.bw = bufio.NewWriterSize(w, c.WriteBufferSize)
+	}
+	d.reset()
+}
 
-	return d
+// Reset discards the state of d, including a partial frame and the number of
+// decoded frames, and makes it write to w with the same options as before.
+// This allows reusing a Decoder instead of allocating a new one.
+func (d *Decoder) Reset(w io.Writer) {
+	d.init(w, d.cfg)
 }
 
 // WriteByte decodes a single byte c. If c is a delimiter the decoder
-// state is validated and either EOD or ErrUnexpectedEOD is returned.
+// state is validated and either EOD or a FrameError wrapping
+// ErrUnexpectedEOD is returned.
+// The delimiter ending a discarded partial frame does not return EOD. With a
+// frame handler the result of the handler is returned instead of EOD.
 func (d *Decoder) WriteByte(c byte) error {
-	// Got a delimiter
-	if c == Delimiter {
-		if d.codeIndex != 0 {
-			return ErrUnexpectedEOD
-		}
-
-		// Reset state
-		d.code = 0xff
-
-		return EOD
+	if d.err != nil {
+		return d.err
 	}
 
-	if d.codeIndex > 0 {
-		if _, err := d.w.Write([]byte{c}); err != nil {
+	if d.timeout > 0 {
+		if err := d.checkTimeout(); err != nil && !d.resync {
 			return err
 		}
-		d.codeIndex--
-
-		return nil
 	}
 
-	d.codeIndex = c
+	d.pos = 0
+	err := d.decode(c)
+	if d.resyncFrame(err, c, false) {
+		err = nil
+	}
 
-	if d.code != 0xff {
-		if _, err := d.w.Write([]byte{Delimiter}); err != nil {
-			return err
-		}
+	if ferr := d.flush(); ferr != nil {
+		err = ferr
 	}
 
-	d.code = d.codeIndex
-	d.codeIndex--
+	if we, ok := err.(*writeError); ok {
+		return we.err
+	}
 
-	return nil
+	return err
 }
 
-// Write will call WriteByte for each byte in p.
-func (d *Decoder) Write(p []byte) (int, error) {
-	for i, c := range p {
-		if err := d.WriteByte(c); err != nil {
-			return i, err
+// WriteString is like Write, but decodes the bytes of s, which avoids
+// converting s to a byte slice. They are copied in chunks that fit on the
+// stack instead.
+func (d *Decoder) WriteString(s string) (int, error) {
+	var chunk [512]byte
+
+	n := 0
+	for len(s) > 0 {
+		m, err := d.Write(chunk[:copy(chunk[:], s)])
+		n += m
+		if err != nil {
+			return n, err
 		}
+		s = s[m:]
 	}
 
-	return len(p), nil
+	return n, nil
 }
 
-// Decode decodes and returns a byte slice.
-func Decode(data []byte) ([]byte, error) {
-	buf := bytes.NewBuffer(make([]byte, 0, len(data)))
-	d := NewDecoder(buf)
+// decode processes a single byte c.
+func (d *Decoder) decode(c byte) error {
+	d.stats.BytesIn++
+
+	// The rest of a delimiter sequence
+	if d.delimRest > 0 {
+		return d.delimTail(c)
+	}
+
+	c = d.unmask(c)
+	if c == Delimiter && len(d.delim) > 1 {
+		d.delimRest = len(d.delim) - 1
+	}
+
+	// Skip a partial frame until it is terminated
+	if d.discard {
+		if c == Delimiter {
+			d.discard = false
+			d.start = int64(d.stats.BytesIn)
+		}
+
+		return nil
+	}
+
+	// Got a delimiter
+	if c == Delimiter {
+		if d.offset == 0 && d.keepAlive != nil {
+			d.start = int64(d.stats.BytesIn)
+
+			return d.keepAlive()
+		}
 
-	_, err := d.Write(data)
+		if d.skipEmpty && d.offset == 0 {
+			d.start = int64(d.stats.BytesIn)
+
+			return nil
+		}
+
+		if err := d.flushReduced(); err != nil {
+			return err
+		}
+
+		if d.codeIndex != 0 && d.strict {
+			n := d.groupLen(d.code)
+			err := &GroupError{Code: d.code, Want: n, Got: n - int(d.codeIndex)}
+
+			return d.frameError(err, d.codeAt)
+		}
+
+		if d.codeIndex != 0 {
+			return d.frameError(ErrUnexpectedEOD, d.offset)
+		}
+
+		if err := d.checkCanonical(true); err != nil {
+			return err
+		}
+
+		if err := d.flushZeros(); err != nil {
+			return err
+		}
+
+		err := d.endFrame()
+		d.reset()
+
+		if err != nil || d.handler != nil {
+			return err
+		}
+
+		return d.eod
+	}
+
+	// The first byte of a frame starts its timeout
+	if d.offset == 0 && d.timeout > 0 {
+		d.began = d.clock.Now()
+	}
+	d.offset++
+
+	if d.codeIndex > 0 {
+		if err := d.emit(c); err != nil {
+			return err
+		}
+		d.codeIndex--
+
+		return nil
+	}
+
+	d.groups++
+	if d.maxGroups > 0 && d.groups > d.maxGroups {
+		return d.frameError(ErrTooManyGroups, d.offset-1)
+	}
+
+	n := d.groupLen(c)
+	if n < 0 {
+		return d.frameError(ErrInvalidCode, d.offset-1)
+	}
+
+	if err := d.checkCanonical(false); err != nil {
+		return err
+	}
+
+	if err := d.endGroup(); err != nil {
+		return err
+	}
+
+	d.prev = d.code
+	d.code = c
+	d.codeIndex = byte(n)
+	d.codeAt = d.offset - 1
+
+	return nil
+}
+
+// groupLen returns the number of data bytes following code c, or -1 if c is
+// not valid.
+func (d *Decoder) groupLen(c byte) int {
+	if d.second {
+		return secondData(c, d.forbid)
+	}
+
+	if d.zre {
+		return zreData(c)
+	}
+
+	// Codes beyond a limited full group are not used
+	if !d.zpe && c > d.full {
+		return -1
+	}
+
+	return groupData(c, d.zpe)
+}
+
+// endGroup emits the bytes following the group of the previous code.
+func (d *Decoder) endGroup() error {
+	if d.second {
+		return d.endSecond()
+	}
+
+	for n := d.groupZeros(d.code); n > 0; n-- {
+		if err := d.emit(d.inserted); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// groupZeros returns the number of zeros following the group of code c, if
+// another group follows.
+func (d *Decoder) groupZeros(c byte) int {
+	if d.zre {
+		return zreZeros(c)
+	}
+
+	if c == d.full {
+		return 0
+	}
+
+	return groupZeros(c, d.zpe)
+}
+
+// flushZeros emits the zeros following the last group of a frame, except for
+// the zero that ends every frame. Only a COBS/ZPE pair or a COBS/ZRE run
+// leaves any.
+func (d *Decoder) flushZeros() error {
+	if d.offset == 0 {
+		return nil
+	}
+
+	for n := d.groupZeros(d.code); n > 1; n-- {
+		if err := d.emit(d.inserted); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// flushReduced emits the code of an incomplete last group, which holds the
+// last data byte of a COBS/R frame.
+func (d *Decoder) flushReduced() error {
+	if !d.reduced || d.codeIndex == 0 {
+		return nil
+	}
+
+	if err := d.emit(d.code); err != nil {
+		return err
+	}
+	d.codeIndex = 0
+
+	return nil
+}
+
+// emit passes a single decoded byte c on, holding back the checksum.
+func (d *Decoder) emit(c byte) error {
+	if d.native {
+		c = swapSentinel(c, d.sentinel)
+	}
+
+	if d.sum == nil {
+		return d.deliver(c)
+	}
+
+	// The last bytes might be the checksum
+	if len(d.tail) < d.sum.kind.size() {
+		d.tail = append(d.tail, c)
+
+		return nil
+	}
+
+	out := d.tail[0]
+	copy(d.tail, d.tail[1:])
+	d.tail[len(d.tail)-1] = c
+	d.sum.update(out)
+
+	return d.deliver(out)
+}
+
+// deliver forwards a single payload byte c to the output of the frame.
+func (d *Decoder) deliver(c byte) error {
+	// The first byte is the sequence number
+	if d.seq && !d.seqDone {
+		d.seqNum = c
+		d.seqDone = true
+
+		return nil
+	}
+
+	if d.prefix {
+		if ok, err := d.unprefix(c); !ok {
+			return err
+		}
+	}
+
+	if d.maxSize > 0 && d.size >= d.maxSize {
+		return d.frameError(ErrFrameTooLarge, d.offset-1)
+	}
+
+	// Frames for a handler are collected
+	if d.handler != nil {
+		d.frame = append(d.frame, c)
+		d.size++
+
+		return nil
+	}
+
+	// The first byte selects the output of a routed frame
+	if d.route != nil && d.size == 0 {
+		if d.out = d.route(c); d.out == nil {
+			d.out = io.Discard
+		}
+		d.size++
+
+		return nil
+	}
+
+	if err := d.pending(c); err != nil {
+		return err
+	}
+	d.size++
+
+	return nil
+}
+
+// maxPending is the amount of pending output that is forwarded right away.
+const maxPending = 32 << 10
+
+// pending adds c to the output that is forwarded by flush.
+func (d *Decoder) pending(c byte) error {
+	off := len(d.pend)
+	if n := len(d.marks); n == 0 || d.marks[n-1].pos+off-d.marks[n-1].off != d.pos {
+		d.marks = append(d.marks, mark{off: off, pos: d.pos})
+	}
+	d.pend = append(d.pend, c)
+
+	if len(d.pend) >= maxPending {
+		return d.flush()
+	}
+
+	return nil
+}
+
+// flush forwards the pending output to the output of the frame. If that fails
+// a writeError with the position of the first input byte that was not
+// forwarded is returned.
+func (d *Decoder) flush() error {
+	if len(d.pend) == 0 {
+		return nil
+	}
+
+	n, err := writeFull(d.out, d.pend)
+	d.stats.BytesOut += uint64(n)
+
+	if err != nil {
+		d.stats.Errors++
+		if d.metrics != nil {
+			d.metrics.Error(err)
+		}
+		m := d.marks[0]
+		for _, mk := range d.marks {
+			if mk.off > n {
+				break
+			}
+			m = mk
+		}
+		err = &writeError{n: m.pos + n - m.off, err: err}
+	}
+
+	d.pend = d.pend[:0]
+	d.marks = d.marks[:0]
+
+	return err
+}
+
+// Write decodes p, forwarding the decoded data with as few writes as
+// possible. It stops at the first delimiter or error, like WriteByte.
+func (d *Decoder) Write(p []byte) (int, error) {
+	if d.err != nil {
+		return 0, d.err
+	}
+
+	if d.timeout > 0 {
+		if err := d.checkTimeout(); err != nil && !d.resync {
+			return 0, err
+		}
+	}
+
+	for i := 0; i < len(p); {
+		d.pos = i
+
+		var n int
+		var err error
+		lookahead := false
+		if d.strict && d.codeIndex == 0 {
+			err = d.checkGroup(p[i:])
+			lookahead = err != nil
+		}
+		if err == nil {
+			n, err = d.decodeRun(p[i:])
+		}
+		if err == nil && n == 0 {
+			if err = d.decode(p[i]); err == nil {
+				n = 1
+			}
+		}
+
+		// Continue after a dropped frame, past the byte that was decoded
+		if err != nil && d.resyncFrame(err, p[i], lookahead) {
+			if !lookahead {
+				i++
+			}
+
+			continue
+		}
+
+		if err != nil {
+			if ferr := d.flush(); ferr != nil {
+				err = ferr
+			}
+
+			if we, ok := err.(*writeError); ok {
+				return we.n, we.err
+			}
+
+			return i + n, err
+		}
+
+		i += n
+	}
+
+	if err := d.flush(); err != nil {
+		we := err.(*writeError)
+
+		return we.n, we.err
+	}
+
+	return len(p), nil
+}
+
+// decodeRun decodes the data bytes of the current group at the start of p
+// at once and returns the number of bytes decoded. Bytes that need more than
+// a copy are left to decode, in which case 0 is returned.
+func (d *Decoder) decodeRun(p []byte) (int, error) {
+	if d.discard || d.native || d.codeIndex == 0 || d.sum != nil || d.prefix || (d.seq && !d.seqDone) ||
+		(d.route != nil && d.size == 0 && d.handler == nil) {
+		return 0, nil
+	}
+
+	run := p
+	if len(run) > int(d.codeIndex) {
+		run = run[:d.codeIndex]
+	}
+	if j := bytes.IndexByte(run, d.sentinel); j != -1 {
+		run = run[:j]
+	}
+	// The byte exceeding the limit is reported by decode
+	if d.maxSize > 0 && d.size+len(run) > d.maxSize {
+		run = run[:d.maxSize-d.size]
+	}
+	if len(run) == 0 {
+		return 0, nil
+	}
+
+	d.offset += len(run)
+	d.size += len(run)
+	d.codeIndex -= byte(len(run))
+	d.stats.BytesIn += uint64(len(run))
+
+	// Frames for a handler are collected
+	if d.handler != nil {
+		d.frame = appendXOR(d.frame, run, d.sentinel)
+
+		return len(run), nil
+	}
+
+	if n := len(d.marks); n == 0 || d.marks[n-1].pos+len(d.pend)-d.marks[n-1].off != d.pos {
+		d.marks = append(d.marks, mark{off: len(d.pend), pos: d.pos})
+	}
+	d.pend = appendXOR(d.pend, run, d.sentinel)
+
+	if len(d.pend) >= maxPending {
+		return len(run), d.flush()
+	}
+
+	return len(run), nil
+}
+
+// appendXOR appends p XORed with x to dst.
+func appendXOR(dst, p []byte, x byte) []byte {
+	n := len(dst)
+	dst = append(dst, p...)
+
+	if x != 0 {
+		xorBytes(dst[n:], x)
+	}
+
+	return dst
+}
+
+// xorBytes XORs every byte of p with x, eight bytes at a time.
+func xorBytes(p []byte, x byte) {
+	w := uint64(x) * 0x0101010101010101
+
+	for len(p) >= 8 {
+		binary.LittleEndian.PutUint64(p, binary.LittleEndian.Uint64(p)^w)
+		p = p[8:]
+	}
+
+	for i := range p {
+		p[i] ^= x
+	}
+}
+
+// frameError returns a FrameError for err at offset in the current frame.
+func (d *Decoder) frameError(err error, offset int) error {
+	if d.vars != nil {
+		d.vars.errors.Add(1)
+	}
+	d.stats.Errors++
+	if d.hist != nil {
+		d.hist.drop()
+	}
+
+	fe := &FrameError{Err: err, Offset: offset, Frame: d.frames, InputOffset: d.start + int64(offset)}
+	if d.metrics != nil {
+		d.metrics.Error(fe)
+	}
+
+	return fe
+}
+
+// endFrame is called for every complete frame.
+func (d *Decoder) endFrame() error {
+	// A lone delimiter carries no checksum, like it carries no length prefix
+	if d.sum != nil && d.offset > 0 {
+		var b [4]byte
+		if !bytes.Equal(d.sum.sum(b[:0]), d.tail) {
+			return d.frameError(ErrChecksumMismatch, d.offset)
+		}
+	}
+
+	if d.prefix && d.offset > 0 && (!d.lenDone || d.length != 0) {
+		return d.frameError(ErrInvalidLength, d.offset)
+	}
+
+	if d.seq {
+		if err := d.endSequence(); err != nil {
+			return err
+		}
+	}
+
+	d.frames++
+
+	if d.hist != nil || d.metrics != nil {
+		d.recordFrame()
+	}
+
+	if d.vars != nil {
+		d.vars.frames.Add(1)
+		d.vars.bytes.Add(int64(d.size))
+	}
+
+	if d.handler != nil {
+		d.stats.BytesOut += uint64(len(d.frame))
+
+		return d.handler(d.frame)
+	}
+
+	if d.newline {
+		if err := d.pending('\n'); err != nil {
+			return err
+		}
+	}
+
+	for _, c := range d.sep {
+		if err := d.pending(c); err != nil {
+			return err
+		}
+	}
+
+	if err := d.flush(); err != nil {
+		return err
+	}
+
+	return d.flushBuffer()
+}
+
+// flushBuffer writes the output buffered by WithWriteBufferSize.
+func (d *Decoder) flushBuffer() error {
+	if d.bw == nil {
+		return nil
+	}
+
+	return d.bw.Flush()
+}
+
+// Remaining returns the number of data bytes the Decoder expects before the
+// next code byte, to complete the current group. It is 0 if the next byte is
+// a code byte or a delimiter. As the length of a frame is not encoded, only
+// the current group is known.
+func (d *Decoder) Remaining() int {
+	return int(d.codeIndex)
+}
+
+// NeedsMoreData reports whether the Decoder is in the middle of a group, so
+// ending the frame would result in an error. A COBS/R frame can end anytime.
+func (d *Decoder) NeedsMoreData() bool {
+	return d.codeIndex != 0 && !d.reduced
+}
+
+// FramesDecoded returns the number of complete frames the Decoder decoded,
+// ended by a delimiter or Close.
+func (d *Decoder) FramesDecoded() uint64 {
+	return d.frames
+}
+
+func (d *Decoder) reset() {
+	d.code = d.full
+	d.prev = 0
+	d.codeIndex = 0
+	d.offset = 0
+	d.size = 0
+	d.groups = 0
+	d.seqDone = false
+	d.lenDone = false
+	d.lenShift = 0
+	d.length = 0
+	d.start = int64(d.stats.BytesIn)
+	d.out = d.w
+	if d.bw != nil {
+		d.out = d.bw
+	}
+	d.frame = d.frame[:0]
+	d.tail = d.tail[:0]
+	if d.sum != nil {
+		d.sum.reset()
+	}
+}
+
+// Close ends a frame that is not terminated by a delimiter. A FrameError
+// wrapping ErrIncompleteFrame is returned if the frame ended in the middle
+// of a group. The Decoder is reset and can be used for a new frame.
+func (d *Decoder) Close() error {
+	if d.err != nil {
+		return d.err
+	}
+
+	d.pos = 0
+	err := d.flushReduced()
+
+	if err == nil && d.codeIndex != 0 {
+		err = d.frameError(ErrIncompleteFrame, d.offset)
+	}
+
+	if err == nil {
+		err = d.flushZeros()
+	}
+
+	if err == nil && d.offset > 0 {
+		err = d.endFrame()
+	}
+
+	if ferr := d.flush(); ferr != nil {
+		err = ferr
+	} else if ferr := d.flushBuffer(); ferr != nil {
+		err = ferr
+	}
+
+	d.reset()
+
+	if we, ok := err.(*writeError); ok {
+		err = we.err
+	}
+
+	if c, ok := d.w.(io.Closer); ok && d.closeW {
+		if cerr := c.Close(); err == nil {
+			err = cerr
+		}
+	}
+
+	return err
+}
+
+// Decode decodes data and returns the result as the type of data, like
+// Encode.
+func Decode[T Bytes](data T, opts ...Option) (T, error) {
+	switch p := any(data).(type) {
+	case []byte:
+		dec, err := decodeBytes(p, opts)
+
+		return any(dec).(T), err
+	case string:
+		dec, err := DecodeString(p, opts...)
+
+		return any(dec).(T), err
+	}
+
+	dec, err := decodeBytes([]byte(data), opts)
+
+	return T(dec), err
+}
+
+// decodeBytes decodes data into a new byte slice.
+func decodeBytes(data []byte, opts []Option) ([]byte, error) {
+	buf := getBuffer()
+	d := newDecoder(buf, opts...)
+
+	if _, err := d.Write(data); err != nil {
+		return putBuffer(buf), err
+	}
+
+	err := d.Close()
+
+	return putBuffer(buf), err
+}
+
+// EncodeString is like Encode, but encodes s and returns a string.
+func EncodeString(s string, opts ...Option) (string, error) {
+	var sb strings.Builder
+	sb.Grow(MaxEncodedLen(len(s), opts...))
+
+	e := newEncoder(&sb, append(opts[:len(opts):len(opts)], WithRateLimit(0))...)
+	if _, err := e.WriteString(s); err != nil {
+		return sb.String(), err
+	}
+
+	err := e.Close()
+
+	return sb.String(), err
+}
+
+// DecodeString is like Decode, but decodes s and returns a string. Neither s
+// nor the result is converted to a byte slice, the string is built from a
+// pooled buffer holding the decoded data.
+func DecodeString(s string, opts ...Option) (string, error) {
+	buf := getBuffer()
+	defer releaseBuffer(buf)
+
+	d := newDecoder(buf, opts...)
+	if _, err := d.WriteString(s); err != nil {
+		return buf.String(), err
+	}
+
+	err := d.Close()
+
+	return buf.String(), err
+}
+
+// DecodeAuto decodes a frame that is encoded with either standard COBS or
+// COBS/R and reports whether COBS/R was used. Standard decoding is tried
+// first, if it fails COBS/R decoding is tried. Both variants decode a valid
+// frame to the same data, unless the last group was reduced, which makes the
+// frame invalid for standard decoding. As a consequence a standard frame that
+// was truncated in its last group is accepted as COBS/R frame. If both fail,
+// the error of standard decoding is returned.
+func DecodeAuto(data []byte, opts ...Option) ([]byte, bool, error) {
+	opts = opts[:len(opts):len(opts)]
+
+	dec, err := Decode(data, append(opts, WithReduced(false))...)
+	if err == nil {
+		return dec, false, nil
+	}
+
+	if red, rerr := Decode(data, append(opts, WithReduced(true))...); rerr == nil {
+		return red, true, nil
+	}
+
+	return dec, false, err
+}
+
+// IsEncoded reports whether data is a single valid encoded frame, optionally
+// terminated by a delimiter. This is a structural check only, data that was
+// never encoded might be a valid frame by chance.
+func IsEncoded(data []byte, opts ...Option) bool {
+	c := newConfig(opts)
+
+	if n := len(data); n > 0 && data[n-1] == c.Sentinel {
+		data = data[:n-1]
+	}
+
+	if len(data) == 0 || bytes.IndexByte(data, c.Sentinel) != -1 {
+		return false
+	}
+
+	d := newDecoder(io.Discard, opts...)
+	if _, err := d.Write(data); err != nil {
+		return false
+	}
 
-	return buf.Bytes(), err
+	return d.Close() == nil
 }
@@ -2,8 +2,13 @@ package cobs
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"io"
+	"strings"
 	"testing"
+	"testing/iotest"
 )
 
 var testCases = []struct {
@@ -134,6 +139,126 @@ var testCases = []struct {
 	},
 }
 
+var reducedCases = []struct {
+	name     string
+	dec, enc []byte
+}{
+	{
+		name: "Empty",
+		dec:  []byte{},
+		enc:  []byte{0x01},
+	},
+	{
+		name: "1 character",
+		dec:  []byte{'1'},
+		enc:  []byte{'1'},
+	},
+	{
+		name: "1 zero",
+		dec:  []byte{0x00},
+		enc:  []byte{0x01, 0x01},
+	},
+	{
+		name: "Small last byte",
+		dec:  []byte{0x02},
+		enc:  []byte{0x02, 0x02},
+	},
+	{
+		name: "Larger last byte",
+		dec:  []byte{0x03},
+		enc:  []byte{0x03},
+	},
+	{
+		name: "5 characters",
+		dec:  []byte("12345"),
+		enc:  []byte("51234"),
+	},
+	{
+		name: "Embedded zero",
+		dec:  []byte("12345\x006789"),
+		enc:  []byte("\x0612345\x39678"),
+	},
+	{
+		name: "Embedded and trailing zero",
+		dec:  []byte("12345\x006789\x00"),
+		enc:  []byte("\x0612345\x056789\x01"),
+	},
+	{
+		name: "Last byte 0xff",
+		dec:  []byte("1234\xff"),
+		enc:  []byte("\xff1234"),
+	},
+	{
+		name: "Reduced to full group code",
+		dec:  append(bytes.Repeat([]byte{'a'}, 252), 0xff),
+		enc:  append([]byte{0xff}, bytes.Repeat([]byte{'a'}, 252)...),
+	},
+	{
+		name: "Full group",
+		dec:  bytes.Repeat([]byte{'a'}, 254),
+		enc:  append([]byte{0xff}, bytes.Repeat([]byte{'a'}, 254)...),
+	},
+	{
+		name: "Reduced after full group",
+		dec:  append(bytes.Repeat([]byte{'a'}, 254), 0x80),
+		enc:  append(append([]byte{0xff}, bytes.Repeat([]byte{'a'}, 254)...), 0x80),
+	},
+	{
+		name: "Small last byte after full group",
+		dec:  append(bytes.Repeat([]byte{'a'}, 254), 0x01),
+		enc:  append(append([]byte{0xff}, bytes.Repeat([]byte{'a'}, 254)...), 0x02, 0x01),
+	},
+	{
+		name: "Zero after full group",
+		dec:  append(bytes.Repeat([]byte{'a'}, 254), 0x00),
+		enc:  append(append([]byte{0xff}, bytes.Repeat([]byte{'a'}, 254)...), 0x01, 0x01),
+	},
+}
+
+var unexpectedDelimiter = []struct {
+	name   string
+	enc    []byte
+	offset int
+}{
+	{
+		name:   "Delimiter in first group",
+		enc:    []byte("\x03a\x00"),
+		offset: 2,
+	},
+	{
+		name:   "Delimiter in second group",
+		enc:    []byte("\x02a\x04bc\x00"),
+		offset: 5,
+	},
+	{
+		name:   "Delimiter after full group code",
+		enc:    []byte("\xff\x00"),
+		offset: 1,
+	},
+}
+
+var incompleteFrame = []struct {
+	name   string
+	enc    []byte
+	offset int
+}{
+	{
+		name:   "Code only",
+		enc:    []byte("\x02"),
+		offset: 1,
+	},
+	{
+		name:   "Truncated first group",
+		enc:    []byte("\x0612"),
+		offset: 3,
+	},
+	{
+		name:   "Truncated second group",
+		enc:    []byte("\x0612345\x056"),
+		offset: 8,
+	},
+}
+
 func TestEncode(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -162,11 +287,188 @@ func TestDecode(t *testing.T) {
 	}
 }
 
+func TestDecodeUnexpectedDelimiter(t *testing.T) {
+	for _, tc := range unexpectedDelimiter {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Decode(tc.enc)
+			if !errors.Is(err, ErrUnexpectedEOD) {
+				t.Fatalf("got %v, want %v", err, ErrUnexpectedEOD)
+			}
+
+			var fe *FrameError
+			if !errors.As(err, &fe) {
+				t.Fatalf("got %T, want *FrameError", err)
+			}
+			if fe.Offset != tc.offset {
+				t.Errorf("offset got %d, want %d", fe.Offset, tc.offset)
+			}
+		})
+	}
+}
+
+func TestDecodeIncompleteFrame(t *testing.T) {
+	for _, tc := range incompleteFrame {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Decode(tc.enc)
+			if !errors.Is(err, ErrIncompleteFrame) {
+				t.Fatalf("got %v, want %v", err, ErrIncompleteFrame)
+			}
+
+			var fe *FrameError
+			if !errors.As(err, &fe) {
+				t.Fatalf("got %T, want *FrameError", err)
+			}
+			if fe.Offset != tc.offset {
+				t.Errorf("offset got %d, want %d", fe.Offset, tc.offset)
+			}
+		})
+	}
+}
+
+func TestEncodeReduced(t *testing.T) {
+	for _, tc := range reducedCases {
+		t.Run(tc.name, func(t *testing.T) {
+			enc, err := Encode(tc.dec, WithReduced(true))
+			if err != nil {
+				t.Errorf("encode error: %v", err)
+			}
+			if !bytes.Equal(enc, tc.enc) {
+				t.Errorf("got %v, want %v", enc, tc.enc)
+			}
+		})
+	}
+}
+
+func TestFrameErrorInputOffset(t *testing.T) {
+	// Two valid frames, skipped delimiters and a malformed third frame
+	data := []byte("\x02a\x00\x00\x00\x03bc\x00\x04de\x00")
+
+	var fe *FrameError
+	_, err := CopyFramesContext(context.Background(), io.Discard, bytes.NewReader(data), WithSkipEmptyFrames(true))
+	if !errors.As(err, &fe) {
+		t.Fatalf("got %v, want FrameError", err)
+	}
+
+	want := FrameError{Err: ErrUnexpectedEOD, Offset: 3, Frame: 2, InputOffset: 12}
+	if *fe != want {
+		t.Errorf("got %+v, want %+v", *fe, want)
+	}
+
+	msg := "unexpected EOD at offset 3 of frame 2 (input offset 12)"
+	if fe.Error() != msg {
+		t.Errorf("got %q, want %q", fe.Error(), msg)
+	}
+}
+
+func TestDecodeReduced(t *testing.T) {
+	for _, tc := range reducedCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dec, err := Decode(tc.enc, WithReduced(true))
+			if err != nil {
+				t.Errorf("decode error: %v", err)
+			}
+			if !bytes.Equal(dec, tc.dec) {
+				t.Errorf("got %v, want %v", dec, tc.dec)
+			}
+
+			// Byte by byte, followed by a delimiter
+			var buf bytes.Buffer
+			d := mustDecoder(t, &buf, WithReduced(true))
+			if _, err := writeBytes(d, append(tc.enc[:len(tc.enc):len(tc.enc)], Delimiter)); err != EOD {
+				t.Errorf("got %v, want %v", err, EOD)
+			}
+			if !bytes.Equal(buf.Bytes(), tc.dec) {
+				t.Errorf("bytes got %v, want %v", buf.Bytes(), tc.dec)
+			}
+		})
+	}
+}
+
+func TestEncodeDecodeString(t *testing.T) {
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			enc, err := EncodeString(string(tc.dec))
+			if err != nil {
+				t.Errorf("encode error: %v", err)
+			}
+			if enc != string(tc.enc) {
+				t.Errorf("encode got %q, want %q", enc, tc.enc)
+			}
+
+			dec, err := DecodeString(string(tc.enc))
+			if err != nil {
+				t.Errorf("decode error: %v", err)
+			}
+			if dec != string(tc.dec) {
+				t.Errorf("decode got %q, want %q", dec, tc.dec)
+			}
+		})
+	}
+
+	if _, err := DecodeString(""); err != nil {
+		t.Errorf("decode empty string error: %v", err)
+	}
+}
+
+func TestEncodeDecodeGeneric(t *testing.T) {
+	type raw []byte
+	type text string
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if enc, err := Encode(string(tc.dec)); err != nil || enc != string(tc.enc) {
+				t.Errorf("string: got %q, %v, want %q", enc, err, tc.enc)
+			}
+			if dec, err := Decode(string(tc.enc)); err != nil || dec != string(tc.dec) {
+				t.Errorf("string: got %q, %v, want %q", dec, err, tc.dec)
+			}
+
+			if enc, err := Encode(raw(tc.dec)); err != nil || !bytes.Equal(enc, tc.enc) {
+				t.Errorf("raw: got %q, %v, want %q", enc, err, tc.enc)
+			}
+			if dec, err := Decode(text(tc.enc)); err != nil || dec != text(tc.dec) {
+				t.Errorf("text: got %q, %v, want %q", dec, err, tc.dec)
+			}
+		})
+	}
+}
+
+func TestDecoderWriteString(t *testing.T) {
+	data := bytes.Repeat([]byte("a frame\x00spanning chunks"), 100)
+	enc, err := Encode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	d := mustDecoder(t, &buf)
+
+	n, err := d.WriteString(string(enc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != len(enc) {
+		t.Errorf("length got %d, want %d", n, len(enc))
+	}
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Errorf("got %q, want %q", buf.Bytes(), data)
+	}
+
+	// A delimiter ends the write like with Write
+	n, err = mustDecoder(t, io.Discard).WriteString(string(enc) + "\x00\x02a")
+	if err != EOD || n != len(enc) {
+		t.Errorf("got %d, %v, want %d, %v", n, err, len(enc), EOD)
+	}
+}
+
 func TestWriter(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			buf := bytes.NewBuffer(make([]byte, 0, len(tc.enc)))
-			e := NewEncoder(NewDecoder(buf))
+			e := mustEncoder(t, mustDecoder(t, buf))
 
 			n, err := e.Write(tc.dec)
 			if err != nil {
@@ -186,12 +488,100 @@ func TestWriter(t *testing.T) {
 	}
 }
 
+func TestWriteString(t *testing.T) {
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			e := mustEncoder(t, &buf)
+
+			n, err := io.WriteString(e, string(tc.dec))
+			if err != nil {
+				t.Errorf("write string error: %v", err)
+			}
+			if n != len(tc.dec) {
+				t.Errorf("length got %d, want %d", n, len(tc.dec))
+			}
+			if err := e.Close(); err != nil {
+				t.Errorf("close error: %v", err)
+			}
+			if !bytes.Equal(buf.Bytes(), tc.enc) {
+				t.Errorf("got %v, want %v", buf.Bytes(), tc.enc)
+			}
+		})
+	}
+}
+
+func TestWriteFrame(t *testing.T) {
+	frames := [][]byte{[]byte("12345"), {}, []byte("67\x0089")}
+
+	for _, opts := range [][]Option{nil, {WithDelimiterOnClose(true)}, {WithReduced(true)}} {
+		var buf bytes.Buffer
+		e := mustEncoder(t, &buf, opts...)
+
+		for _, frame := range frames {
+			n, err := e.WriteFrame(frame)
+			if err != nil {
+				t.Fatalf("write frame error: %v", err)
+			}
+			if n != len(frame) {
+				t.Errorf("length got %d, want %d", n, len(frame))
+			}
+		}
+
+		got, err := DecodeFrames(buf.Bytes(), opts...)
+		if err != nil {
+			t.Fatalf("decode error: %v", err)
+		}
+		if len(got) != len(frames) {
+			t.Fatalf("got %d frames, want %d", len(got), len(frames))
+		}
+		for i := range frames {
+			if !bytes.Equal(got[i], frames[i]) {
+				t.Errorf("frame %d: got %q, want %q", i, got[i], frames[i])
+			}
+		}
+	}
+}
+
+func TestEncoderBuffered(t *testing.T) {
+	var buf bytes.Buffer
+	e := mustEncoder(t, &buf)
+
+	if _, err := e.Write([]byte("1234")); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	if got := e.Buffered(); got != 4 || buf.Len() != 0 {
+		t.Errorf("got %d buffered, %d written, want 4, 0", got, buf.Len())
+	}
+
+	// A full group is written with the next byte
+	if _, err := e.Write(bytes.Repeat([]byte{'a'}, 250)); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	if got := e.Buffered(); got != 254 || buf.Len() != 0 {
+		t.Errorf("got %d buffered, %d written, want 254, 0", got, buf.Len())
+	}
+	if err := e.WriteByte('b'); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	if got := e.Buffered(); got != 1 || buf.Len() != 255 {
+		t.Errorf("got %d buffered, %d written, want 1, 255", got, buf.Len())
+	}
+
+	if err := e.Close(); err != nil {
+		t.Fatalf("close error: %v", err)
+	}
+	if got := e.Buffered(); got != 0 {
+		t.Errorf("got %d buffered, want 0", got)
+	}
+}
+
 func TestStream(t *testing.T) {
 	pr, pw := io.Pipe()
 
 	go func() {
 		defer pw.Close()
-		e := NewEncoder(pw)
+		e := mustEncoder(t, pw)
 
 		for _, tc := range testCases {
 			_, err := e.Write(tc.dec)
@@ -211,7 +601,7 @@ func TestStream(t *testing.T) {
 	}()
 
 	var buf bytes.Buffer
-	d := NewDecoder(&buf)
+	d := mustDecoder(t, &buf)
 
 	for _, tc := range testCases {
 		_, err := io.Copy(d, pr)
@@ -232,10 +622,14 @@ func TestStream(t *testing.T) {
 
 func FuzzEncodeDecode(f *testing.F) {
 	for _, tc := range testCases {
-		f.Add(tc.dec)
+		f.Add(tc.dec, false)
+		f.Add(tc.dec, true)
 	}
-	f.Fuzz(func(t *testing.T, a []byte) {
-		enc, err := Encode(a)
+	for _, tc := range reducedCases {
+		f.Add(tc.dec, true)
+	}
+	f.Fuzz(func(t *testing.T, a []byte, reduced bool) {
+		enc, err := Encode(a, WithReduced(reduced))
 		if err != nil {
 			t.Errorf("fuzz encode error: %v", err)
 		}
@@ -243,7 +637,17 @@ func FuzzEncodeDecode(f *testing.F) {
 			t.Errorf("fuzz encode %v has delimiter at %d", enc, i)
 		}
 
-		dec, err := Decode(enc)
+		if reduced {
+			std, err := Encode(a)
+			if err != nil {
+				t.Errorf("fuzz encode error: %v", err)
+			}
+			if len(enc) > len(std) {
+				t.Errorf("fuzz reduced length %d longer than %d", len(enc), len(std))
+			}
+		}
+
+		dec, err := Decode(enc, WithReduced(reduced))
 		if err != nil {
 			t.Errorf("fuzz decode error: %v", err)
 		}
@@ -255,11 +659,16 @@ func FuzzEncodeDecode(f *testing.F) {
 
 func FuzzChainWriter(f *testing.F) {
 	for _, tc := range testCases {
-		f.Add(tc.dec)
+		f.Add(tc.dec, false)
+		f.Add(tc.dec, true)
 	}
-	f.Fuzz(func(t *testing.T, a []byte) {
+	for _, tc := range reducedCases {
+		f.Add(tc.dec, true)
+	}
+	f.Fuzz(func(t *testing.T, a []byte, reduced bool) {
 		var buf bytes.Buffer
-		e := NewEncoder(NewDecoder(&buf))
+		d := mustDecoder(t, &buf, WithReduced(reduced))
+		e := mustEncoder(t, d, WithReduced(reduced))
 
 		n, err := e.Write(a)
 		if err != nil {
@@ -273,8 +682,1123 @@ func FuzzChainWriter(f *testing.F) {
 		if err != nil {
 			t.Errorf("fuzz chain close error: %v", err)
 		}
+		err = d.Close()
+		if err != nil {
+			t.Errorf("fuzz chain decoder close error: %v", err)
+		}
 		if !bytes.Equal(buf.Bytes(), a) {
 			t.Errorf("fuzz chain got %v want %v", buf.Bytes(), a)
 		}
 	})
 }
+
+func TestDiscardFirstPartial(t *testing.T) {
+	// Start in the middle of the "Embedded zero" frame
+	stream := []byte("345\x056789\x00\x0612345\x00\x02a\x00")
+	want := [][]byte{[]byte("12345"), []byte("a")}
+
+	var buf bytes.Buffer
+	d := mustDecoder(t, &buf, WithDiscardFirstPartial(true))
+
+	var got [][]byte
+	for len(stream) > 0 {
+		n, err := d.Write(stream)
+		if err != EOD {
+			t.Fatalf("discard error: %v", err)
+		}
+
+		got = append(got, append([]byte{}, buf.Bytes()...))
+		buf.Reset()
+		stream = stream[n+1:]
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("discard got %d frames, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !bytes.Equal(got[i], want[i]) {
+			t.Errorf("discard frame %d got %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestMaxFrameSize(t *testing.T) {
+	// "Embedded zero" decodes to 10 bytes
+	enc := []byte("\x0612345\x056789")
+
+	var buf bytes.Buffer
+	d := mustDecoder(t, &buf, WithMaxFrameSize(10))
+	if _, err := d.Write(enc); err != nil {
+		t.Errorf("at limit error: %v", err)
+	}
+	if err := d.Close(); err != nil {
+		t.Errorf("at limit close error: %v", err)
+	}
+
+	buf.Reset()
+	d = mustDecoder(t, &buf, WithMaxFrameSize(9))
+	n, err := d.Write(enc)
+	if !errors.Is(err, ErrFrameTooLarge) {
+		t.Fatalf("over limit got %v, want %v", err, ErrFrameTooLarge)
+	}
+	if n != len(enc)-1 {
+		t.Errorf("over limit consumed %d, want %d", n, len(enc)-1)
+	}
+	if buf.Len() != 9 {
+		t.Errorf("over limit wrote %d bytes, want 9", buf.Len())
+	}
+
+	// The limit applies per frame
+	buf.Reset()
+	d = mustDecoder(t, &buf, WithMaxFrameSize(5))
+	for i := 0; i < 3; i++ {
+		if _, err := d.Write([]byte("\x0612345\x00")); err != EOD {
+			t.Errorf("frame %d got %v, want %v", i, err, EOD)
+		}
+	}
+}
+
+func TestTypeRouter(t *testing.T) {
+	var bufs [3]bytes.Buffer
+	route := func(typeByte byte) io.Writer {
+		if int(typeByte) >= len(bufs) {
+			return nil
+		}
+
+		return &bufs[typeByte]
+	}
+
+	d := mustDecoder(t, nil, WithTypeRouter(route))
+	frames := [][]byte{
+		[]byte("\x01\x03ab\x00"),
+		[]byte("\x02\x01\x02d\x00"),
+		[]byte("\x03\x02c\x00"),
+		[]byte("\x05\x04xyz\x00"),
+		[]byte("\x02\x02\x03ef\x00"),
+	}
+	for i, frame := range frames {
+		if _, err := d.Write(frame); err != EOD {
+			t.Errorf("frame %d got %v, want %v", i, err, EOD)
+		}
+	}
+
+	want := []string{"ab", "\x00d", "c\x00ef"}
+	for i := range bufs {
+		if got := bufs[i].String(); got != want[i] {
+			t.Errorf("type %d got %q, want %q", i, got, want[i])
+		}
+	}
+}
+
+func TestFrameHandler(t *testing.T) {
+	var got [][]byte
+	errStop := errors.New("stop")
+	handler := func(frame []byte) error {
+		if bytes.Equal(frame, []byte("stop")) {
+			return errStop
+		}
+		got = append(got, append([]byte{}, frame...))
+
+		return nil
+	}
+
+	d := mustDecoder(t, nil, WithFrameHandler(handler), WithReduced(true))
+	stream := []byte("\x0612345\x00\x01\x00\x01\x01\x00\x02a\x00psto\x00b")
+
+	n, err := d.Write(stream)
+	if err != errStop {
+		t.Fatalf("got %v, want %v", err, errStop)
+	}
+	if _, err := d.Write(stream[n+1:]); err != nil {
+		t.Fatalf("handler error: %v", err)
+	}
+	if err := d.Close(); err != nil {
+		t.Fatalf("handler close error: %v", err)
+	}
+
+	want := [][]byte{[]byte("12345"), {}, {0x00}, []byte("a"), []byte("b")}
+	if len(got) != len(want) {
+		t.Fatalf("got %d frames, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !bytes.Equal(got[i], want[i]) {
+			t.Errorf("frame %d got %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestAppendNewline(t *testing.T) {
+	stream := []byte("\x02a\x00\x01\x00\x03bc\x00\x03d")
+
+	var buf bytes.Buffer
+	d := mustDecoder(t, &buf, WithAppendNewline(true))
+	for {
+		n, err := d.Write(stream)
+		if err == nil {
+			break
+		}
+		if err != EOD {
+			t.Fatalf("newline error: %v", err)
+		}
+		stream = stream[n+1:]
+	}
+
+	if err := d.Close(); !errors.Is(err, ErrIncompleteFrame) {
+		t.Errorf("got %v, want %v", err, ErrIncompleteFrame)
+	}
+
+	if got := buf.String(); got != "a\n\nbc\nd" {
+		t.Errorf("got %q", got)
+	}
+
+	// A complete frame without delimiter is terminated on Close
+	buf.Reset()
+	if _, err := d.Write([]byte("\x03de")); err != nil {
+		t.Fatalf("newline error: %v", err)
+	}
+	if err := d.Close(); err != nil {
+		t.Fatalf("newline close error: %v", err)
+	}
+	if got := buf.String(); got != "de\n" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestIsEncoded(t *testing.T) {
+	for _, tc := range testCases {
+		if !IsEncoded(tc.enc) {
+			t.Errorf("%s: %v is not encoded", tc.name, tc.enc)
+		}
+		if !IsEncoded(append(tc.enc, Delimiter)) {
+			t.Errorf("%s: %v with delimiter is not encoded", tc.name, tc.enc)
+		}
+	}
+
+	for _, tc := range unexpectedDelimiter {
+		if IsEncoded(tc.enc) {
+			t.Errorf("%s: %v is encoded", tc.name, tc.enc)
+		}
+	}
+	for _, tc := range incompleteFrame {
+		if IsEncoded(tc.enc) {
+			t.Errorf("%s: %v is encoded", tc.name, tc.enc)
+		}
+	}
+
+	// Raw data
+	if IsEncoded([]byte{}) || IsEncoded([]byte("12345\x006789")) || IsEncoded([]byte("Hello")) {
+		t.Error("raw data is encoded")
+	}
+
+	// Ambiguous data is a valid frame by chance
+	if !IsEncoded([]byte("\x03ab")) {
+		t.Error("ambiguous data is not encoded")
+	}
+
+	if !IsEncoded([]byte("Hello"), WithReduced(true)) {
+		t.Error("reduced data is not encoded")
+	}
+}
+
+func TestEncodeDecodeNoAliasing(t *testing.T) {
+	enc1, _ := Encode([]byte("first"))
+	enc2, _ := Encode([]byte("other"))
+	if string(enc1) != "\x06first" || string(enc2) != "\x06other" {
+		t.Errorf("encode got %q and %q", enc1, enc2)
+	}
+
+	dec1, _ := Decode(enc1)
+	dec2, _ := Decode(enc2)
+	if string(dec1) != "first" || string(dec2) != "other" {
+		t.Errorf("decode got %q and %q", dec1, dec2)
+	}
+}
+
+func BenchmarkEncode(b *testing.B) {
+	data := []byte("a small\x00packet")
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := Encode(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecode(b *testing.B) {
+	data := []byte("\x08a small\x07packet")
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := Decode(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeString(b *testing.B) {
+	s, err := EncodeString(strings.Repeat(`{"key":"value"}`+"\x00", 1000))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := DecodeString(s); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestInsertedByte(t *testing.T) {
+	testCases := []struct {
+		name     string
+		inserted byte
+		dec, enc []byte
+	}{
+		{
+			name:     "Zero",
+			inserted: 0x00,
+			dec:      []byte("ab\x00c~d"),
+			enc:      []byte("\x03ab\x04c~d"),
+		},
+		{
+			name:     "Custom value",
+			inserted: '~',
+			dec:      []byte("ab\x01c~d"),
+			enc:      []byte("\x05ab\x01c\x02d"),
+		},
+		{
+			name:     "Custom value only",
+			inserted: '~',
+			dec:      []byte("~~"),
+			enc:      []byte("\x01\x01\x01"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			enc, err := Encode(tc.dec, WithInsertedByte(tc.inserted))
+			if err != nil {
+				t.Errorf("encode error: %v", err)
+			}
+			if !bytes.Equal(enc, tc.enc) {
+				t.Errorf("encode got %v, want %v", enc, tc.enc)
+			}
+
+			dec, err := Decode(enc, WithInsertedByte(tc.inserted))
+			if err != nil {
+				t.Errorf("decode error: %v", err)
+			}
+			if !bytes.Equal(dec, tc.dec) {
+				t.Errorf("decode got %v, want %v", dec, tc.dec)
+			}
+		})
+	}
+}
+
+// limitWriter accepts n bytes and fails afterwards.
+type limitWriter struct {
+	n      int
+	writes int
+	buf    bytes.Buffer
+}
+
+var errLimit = errors.New("limit reached")
+
+func (w *limitWriter) Write(p []byte) (int, error) {
+	w.writes++
+
+	if len(p) > w.n {
+		w.buf.Write(p[:w.n])
+		n := w.n
+		w.n = 0
+
+		return n, errLimit
+	}
+
+	w.n -= len(p)
+
+	return w.buf.Write(p)
+}
+
+func TestDecoderBatchedWrites(t *testing.T) {
+	enc := []byte("\x0612345\x056789")
+
+	w := &limitWriter{n: 100}
+	d := mustDecoder(t, w)
+	if _, err := d.Write(enc); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	if w.writes != 1 {
+		t.Errorf("got %d writes, want 1", w.writes)
+	}
+
+	// The output of input byte 8 fails
+	w = &limitWriter{n: 7}
+	d = mustDecoder(t, w)
+	n, err := d.Write(enc)
+	if err != errLimit {
+		t.Fatalf("got %v, want %v", err, errLimit)
+	}
+	if n != 8 {
+		t.Errorf("consumed %d, want 8", n)
+	}
+	if got := w.buf.String(); got != "12345\x006" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func BenchmarkDecoderWrite(b *testing.B) {
+	data := bytes.Repeat([]byte("0123456789abcdef\x00"), 4096)
+	enc, err := Encode(data)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	w := &limitWriter{}
+	b.SetBytes(int64(len(enc)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		w.n = len(data)
+		w.buf.Reset()
+
+		d := mustDecoder(b, w)
+		if _, err := d.Write(enc); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.ReportMetric(float64(w.writes)/float64(b.N), "writes/op")
+}
+
+func TestDecodeAuto(t *testing.T) {
+	for _, tc := range testCases {
+		dec, reduced, err := DecodeAuto(tc.enc)
+		if err != nil || reduced || !bytes.Equal(dec, tc.dec) {
+			t.Errorf("%s: got %v, %t, %v", tc.name, dec, reduced, err)
+		}
+	}
+
+	for _, tc := range reducedCases {
+		enc, err := Encode(tc.dec)
+		if err != nil {
+			t.Fatalf("%s: encode error: %v", tc.name, err)
+		}
+
+		// Only reduced frames are invalid for standard decoding
+		want := !bytes.Equal(enc, tc.enc)
+
+		dec, reduced, err := DecodeAuto(tc.enc)
+		if err != nil || reduced != want || !bytes.Equal(dec, tc.dec) {
+			t.Errorf("%s: got %v, %t, %v", tc.name, dec, reduced, err)
+		}
+	}
+
+	for _, tc := range unexpectedDelimiter {
+		if _, _, err := DecodeAuto(tc.enc); !errors.Is(err, ErrUnexpectedEOD) {
+			t.Errorf("%s: got %v, want %v", tc.name, err, ErrUnexpectedEOD)
+		}
+	}
+}
+
+// writeBytes writes p to d byte by byte, like Decoder.Write.
+func writeBytes(d *Decoder, p []byte) (int, error) {
+	for i, c := range p {
+		if err := d.WriteByte(c); err != nil {
+			return i, err
+		}
+	}
+
+	return len(p), nil
+}
+
+func FuzzDecoderWrite(f *testing.F) {
+	for _, tc := range testCases {
+		f.Add(tc.enc, byte(0), false, 0)
+	}
+	for _, tc := range reducedCases {
+		f.Add(tc.enc, byte(0), true, 3)
+	}
+	for _, tc := range unexpectedDelimiter {
+		f.Add(tc.enc, byte(0), false, 0)
+	}
+	f.Add([]byte("\x41\x23\x20\x42\x41\x42\x42"), byte(0x42), false, 1)
+	f.Fuzz(func(t *testing.T, enc []byte, sentinel byte, reduced bool, maxSize int) {
+		opts := []Option{WithSentinel(sentinel), WithReduced(reduced), WithMaxFrameSize(maxSize)}
+
+		var bulk, single bytes.Buffer
+		db := mustDecoder(t, &bulk, opts...)
+		ds := mustDecoder(t, &single, opts...)
+
+		for p := enc; len(p) > 0; {
+			nb, errb := db.Write(p)
+			ns, errs := writeBytes(ds, p)
+			if nb != ns || fmt.Sprint(errb) != fmt.Sprint(errs) {
+				t.Fatalf("write got %d, %v, want %d, %v", nb, errb, ns, errs)
+			}
+			if errb != EOD {
+				break
+			}
+			p = p[nb+1:]
+		}
+
+		if !bytes.Equal(bulk.Bytes(), single.Bytes()) {
+			t.Errorf("got %v, want %v", bulk.Bytes(), single.Bytes())
+		}
+	})
+}
+
+func FuzzEncoderWrite(f *testing.F) {
+	for _, tc := range testCases {
+		f.Add(tc.dec, byte(0), false, false)
+	}
+	f.Add(bytes.Repeat([]byte{0x01}, 600), byte(0x42), false, true)
+	f.Add([]byte("\x00\x00a\x00\x00\x00b"), byte(0), true, false)
+	f.Fuzz(func(t *testing.T, data []byte, sentinel byte, zpe, reduced bool) {
+		opts := []Option{WithSentinel(sentinel), WithZPE(zpe), WithReduced(reduced && !zpe)}
+
+		var bulk, single bytes.Buffer
+		eb := mustEncoder(t, &bulk, opts...)
+		es := mustEncoder(t, &single, opts...)
+
+		if _, err := eb.Write(data); err != nil {
+			t.Fatalf("write error: %v", err)
+		}
+		for _, c := range data {
+			if err := es.WriteByte(c); err != nil {
+				t.Fatalf("write error: %v", err)
+			}
+		}
+		if err := eb.Close(); err != nil {
+			t.Fatalf("close error: %v", err)
+		}
+		if err := es.Close(); err != nil {
+			t.Fatalf("close error: %v", err)
+		}
+
+		if !bytes.Equal(bulk.Bytes(), single.Bytes()) {
+			t.Errorf("got %v, want %v", bulk.Bytes(), single.Bytes())
+		}
+	})
+}
+
+func BenchmarkDecoderWriteLarge(b *testing.B) {
+	data := bytes.Repeat([]byte("0123456789abcdef"), 64<<10)
+
+	for _, sentinel := range []byte{0x00, 0x42} {
+		b.Run(fmt.Sprintf("sentinel=%#02x", sentinel), func(b *testing.B) {
+			enc, err := Encode(data, WithSentinel(sentinel))
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.SetBytes(int64(len(enc)))
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				d := mustDecoder(b, io.Discard, WithSentinel(sentinel))
+				if _, err := d.Write(enc); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestMaxGroupsPerFrame(t *testing.T) {
+	// Every group holds a single zero
+	enc := bytes.Repeat([]byte{0x01}, 10000)
+
+	var buf bytes.Buffer
+	d := mustDecoder(t, &buf, WithMaxGroupsPerFrame(100))
+
+	n, err := d.Write(enc)
+	if !errors.Is(err, ErrTooManyGroups) {
+		t.Fatalf("got %v, want %v", err, ErrTooManyGroups)
+	}
+
+	var fe *FrameError
+	if !errors.As(err, &fe) || fe.Offset != 100 {
+		t.Errorf("got %v, want offset 100", err)
+	}
+	if n != 100 {
+		t.Errorf("consumed %d, want 100", n)
+	}
+	if buf.Len() != 99 {
+		t.Errorf("got %d bytes, want 99", buf.Len())
+	}
+
+	// The limit applies per frame
+	d = mustDecoder(t, io.Discard, WithMaxGroupsPerFrame(3))
+	for i := 0; i < 3; i++ {
+		if _, err := d.Write([]byte("\x02a\x02b\x02c\x00")); err != EOD {
+			t.Fatalf("frame %d: got %v, want %v", i, err, EOD)
+		}
+	}
+}
+
+func TestMaxGroupSize(t *testing.T) {
+	data := []byte("abcdefg\x00hij")
+	want := []byte("\x04abc\x04def\x02g\x04hij")
+
+	enc, err := Encode(data, WithMaxGroupSize(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(enc, want) {
+		t.Errorf("Encode: got %q, want %q", enc, want)
+	}
+
+	dec, err := Decode(enc, WithMaxGroupSize(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(dec, data) {
+		t.Errorf("Decode: got %q, want %q", dec, data)
+	}
+
+	if err := Validate(enc, WithMaxGroupSize(3)); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+
+	buf := append([]byte(nil), enc...)
+	if n, err := DecodeInPlace(buf, WithMaxGroupSize(3)); err != nil || !bytes.Equal(buf[:n], data) {
+		t.Errorf("DecodeInPlace: got %q, %v", buf[:n], err)
+	}
+
+	// A larger group exceeds the limit
+	_, err = Decode([]byte("\x05abcd"), WithMaxGroupSize(3))
+	if !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("got %v, want %v", err, ErrInvalidCode)
+	}
+
+	if err := CheckOptions(WithMaxGroupSize(3), WithZPE(true)); !errors.Is(err, ErrIncompatibleOptions) {
+		t.Errorf("got %v, want %v", err, ErrIncompatibleOptions)
+	}
+}
+
+func TestFramesDecoded(t *testing.T) {
+	d := mustDecoder(t, io.Discard)
+
+	for _, p := range []string{"\x02a\x00", "\x00", "\x03bc\x00"} {
+		_, _ = d.Write([]byte(p))
+	}
+	if got := d.FramesDecoded(); got != 3 {
+		t.Errorf("got %d frames, want 3", got)
+	}
+
+	// A frame ended by Close counts too
+	_, _ = d.Write([]byte("\x02d"))
+	if err := d.Close(); err != nil {
+		t.Fatalf("close error: %v", err)
+	}
+	if got := d.FramesDecoded(); got != 4 {
+		t.Errorf("got %d frames, want 4", got)
+	}
+}
+
+func TestSkipEmptyFrames(t *testing.T) {
+	testCases := []struct {
+		name string
+		enc  []byte
+		n    int
+	}{
+		{
+			name: "Double delimiter",
+			enc:  []byte("\x00\x00\x02a\x00"),
+			n:    4,
+		},
+		{
+			name: "Leading delimiter",
+			enc:  []byte("\x00\x02a\x00"),
+			n:    3,
+		},
+		{
+			name: "No delimiter",
+			enc:  []byte("\x02a\x00"),
+			n:    2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			d := mustDecoder(t, &buf, WithSkipEmptyFrames(true))
+
+			n, err := d.Write(tc.enc)
+			if err != EOD {
+				t.Fatalf("got %v, want %v", err, EOD)
+			}
+			if n != tc.n {
+				t.Errorf("got %d, want %d", n, tc.n)
+			}
+			if buf.String() != "a" {
+				t.Errorf("got %q, want %q", buf.String(), "a")
+			}
+			if d.FramesDecoded() != 1 {
+				t.Errorf("got %d frames, want 1", d.FramesDecoded())
+			}
+		})
+	}
+}
+
+func TestKeepAlive(t *testing.T) {
+	var events []string
+	handler := func(frame []byte) error {
+		events = append(events, fmt.Sprintf("frame %q", frame))
+
+		return nil
+	}
+	keepAlive := func() error {
+		events = append(events, "keep-alive")
+
+		return nil
+	}
+
+	d := mustDecoder(t, nil, WithFrameHandler(handler), WithKeepAlive(keepAlive), WithSkipEmptyFrames(true))
+
+	// An encoded empty frame differs from a lone delimiter
+	if _, err := d.Write([]byte("\x00\x02a\x00\x00\x01\x00")); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"keep-alive", `frame "a"`, "keep-alive", `frame ""`}
+	if fmt.Sprint(events) != fmt.Sprint(want) {
+		t.Errorf("got %q, want %q", events, want)
+	}
+	if d.FramesDecoded() != 2 {
+		t.Errorf("got %d frames, want 2", d.FramesDecoded())
+	}
+
+	// The error of the function is returned
+	errStop := errors.New("stop")
+	d = mustDecoder(t, io.Discard, WithKeepAlive(func() error { return errStop }))
+	if n, err := d.Write([]byte("\x00\x02a")); err != errStop || n != 0 {
+		t.Errorf("got %d, %v, want 0, %v", n, err, errStop)
+	}
+}
+
+func TestPrefixDelimiter(t *testing.T) {
+	opts := []Option{WithPrefixDelimiter(true), WithDelimiterOnClose(true)}
+
+	var buf bytes.Buffer
+	e := mustEncoder(t, &buf, opts...)
+	for _, tc := range testCases {
+		if _, err := e.Write(tc.dec); err != nil {
+			t.Fatalf("%s: write error: %v", tc.name, err)
+		}
+		if err := e.Close(); err != nil {
+			t.Fatalf("%s: close error: %v", tc.name, err)
+		}
+	}
+
+	if !bytes.HasPrefix(buf.Bytes(), []byte("\x00\x01\x00\x00\x02")) {
+		t.Errorf("got %v, want frames enclosed by delimiters", buf.Bytes()[:5])
+	}
+
+	frames, err := DecodeFrames(buf.Bytes(), opts...)
+	if err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if len(frames) != len(testCases) {
+		t.Fatalf("got %d frames, want %d", len(frames), len(testCases))
+	}
+	for i, tc := range testCases {
+		if !bytes.Equal(frames[i], tc.dec) {
+			t.Errorf("%s: got %v, want %v", tc.name, frames[i], tc.dec)
+		}
+	}
+}
+
+// closeWriter counts the calls of Close.
+type closeWriter struct {
+	bytes.Buffer
+	closed int
+}
+
+func (w *closeWriter) Close() error {
+	w.closed++
+
+	return nil
+}
+
+func TestCloseUnderlying(t *testing.T) {
+	for _, enable := range []bool{false, true} {
+		want := 0
+		if enable {
+			want = 1
+		}
+
+		var w closeWriter
+		e := mustEncoder(t, &w, WithCloseUnderlying(enable))
+		if _, err := e.Write([]byte("abc")); err != nil {
+			t.Fatalf("write error: %v", err)
+		}
+		if err := e.Close(); err != nil {
+			t.Fatalf("close error: %v", err)
+		}
+		if w.closed != want {
+			t.Errorf("encoder closed %d times, want %d", w.closed, want)
+		}
+
+		var dw closeWriter
+		d := mustDecoder(t, &dw, WithCloseUnderlying(enable))
+		if _, err := d.Write(w.Bytes()); err != nil {
+			t.Fatalf("write error: %v", err)
+		}
+		if err := d.Close(); err != nil {
+			t.Fatalf("close error: %v", err)
+		}
+		if dw.closed != want {
+			t.Errorf("decoder closed %d times, want %d", dw.closed, want)
+		}
+		if dw.String() != "abc" {
+			t.Errorf("got %q, want %q", dw.String(), "abc")
+		}
+	}
+}
+
+func TestDecoderRemaining(t *testing.T) {
+	d := mustDecoder(t, io.Discard)
+
+	steps := []struct {
+		p         string
+		remaining int
+	}{
+		{p: "", remaining: 0},
+		{p: "\x06", remaining: 5},
+		{p: "12", remaining: 3},
+		{p: "345", remaining: 0},
+		{p: "\x03a", remaining: 1},
+		{p: "b", remaining: 0},
+	}
+
+	for _, s := range steps {
+		if _, err := d.Write([]byte(s.p)); err != nil {
+			t.Fatalf("write error: %v", err)
+		}
+		if got := d.Remaining(); got != s.remaining {
+			t.Errorf("after %q: got %d, want %d", s.p, got, s.remaining)
+		}
+		if got := d.NeedsMoreData(); got != (s.remaining > 0) {
+			t.Errorf("after %q: needs more data %v", s.p, got)
+		}
+	}
+}
+
+func TestEODasEOF(t *testing.T) {
+	testCases := []struct {
+		name string
+		opts []Option
+		err  error
+	}{
+		{
+			name: "Default",
+			err:  EOD,
+		},
+		{
+			name: "EOF",
+			opts: []Option{WithEODasEOF(true)},
+			err:  io.EOF,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			d := mustDecoder(t, &buf, tc.opts...)
+
+			_, err := io.Copy(d, bytes.NewReader([]byte("\x06Hello\x00\x02a")))
+			if err != tc.err {
+				t.Errorf("got %v, want %v", err, tc.err)
+			}
+			if buf.String() != "Hello" {
+				t.Errorf("got %q, want %q", buf.String(), "Hello")
+			}
+
+			// Malformed frames are still reported as such
+			_, err = io.Copy(d, bytes.NewReader([]byte("\x06Hel\x00")))
+			if !errors.Is(err, ErrUnexpectedEOD) {
+				t.Errorf("got %v, want %v", err, ErrUnexpectedEOD)
+			}
+
+			frames, err := DecodeFrames([]byte("\x02a\x00\x02b\x00"), tc.opts...)
+			if err != nil || len(frames) != 2 {
+				t.Errorf("got %d frames, %v, want 2 frames", len(frames), err)
+			}
+		})
+	}
+}
+
+func TestAutoReset(t *testing.T) {
+	enc, err := EncodeFrames([][]byte{[]byte("Hello"), []byte("a\x00b"), nil})
+	if err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	d := mustDecoder(t, &buf, WithAutoReset(true), WithFrameSeparator([]byte("--")))
+
+	if _, err := io.Copy(d, iotest.HalfReader(bytes.NewReader(enc))); err != nil {
+		t.Fatalf("copy error: %v", err)
+	}
+	if got, want := buf.String(), "Hello--a\x00b----"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := d.FramesDecoded(); got != 3 {
+		t.Errorf("got %d frames, want 3", got)
+	}
+
+	// Errors still stop decoding
+	if _, err := d.Write([]byte("\x02a\x00\x03a\x00")); !errors.Is(err, ErrUnexpectedEOD) {
+		t.Errorf("got %v, want %v", err, ErrUnexpectedEOD)
+	}
+
+	// Helpers splitting frames are not affected
+	frames, err := DecodeFrames(enc, WithAutoReset(true))
+	if err != nil || len(frames) != 3 {
+		t.Errorf("got %d frames, %v, want 3 frames", len(frames), err)
+	}
+}
+
+func TestWriteBufferSize(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789abcdef"), 1024)
+	want, err := Encode(data, WithDelimiterOnClose(true))
+	if err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+
+	for _, size := range []int{0, 1, 100, 4096} {
+		w := &limitWriter{n: len(want)}
+		e := mustEncoder(t, w, WithWriteBufferSize(size), WithDelimiterOnClose(true))
+
+		if _, err := e.Write(data); err != nil {
+			t.Fatalf("size %d: write error: %v", size, err)
+		}
+		if err := e.Close(); err != nil {
+			t.Fatalf("size %d: close error: %v", size, err)
+		}
+
+		if !bytes.Equal(w.buf.Bytes(), want) {
+			t.Errorf("size %d: output differs", size)
+		}
+
+		// Writes are bounded by the groups or the buffer size
+		limit := len(want)/255 + 2
+		if size > 255 {
+			limit = len(want)/size + 1
+		}
+		if w.writes > limit {
+			t.Errorf("size %d: got %d writes, want at most %d", size, w.writes, limit)
+		}
+	}
+}
+
+func TestDecoderWriteBufferSize(t *testing.T) {
+	frames := [][]byte{[]byte("Hello\x00world"), bytes.Repeat([]byte("0123456789"), 50)}
+	enc, err := EncodeFrames(frames)
+	if err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+
+	w := &limitWriter{n: 1 << 20}
+	d := mustDecoder(t, w, WithWriteBufferSize(4096))
+
+	// Byte by byte writes are collected until the end of a frame
+	for _, c := range enc {
+		if err := d.WriteByte(c); err != nil && err != EOD {
+			t.Fatalf("write error: %v", err)
+		}
+	}
+
+	if got, want := w.buf.String(), string(bytes.Join(frames, nil)); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if w.writes != len(frames) {
+		t.Errorf("got %d writes, want %d", w.writes, len(frames))
+	}
+
+	// Close writes the data of an unterminated frame
+	if err := d.WriteByte(0x02); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	if err := d.WriteByte('a'); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	if w.writes != len(frames) {
+		t.Errorf("got %d writes before close, want %d", w.writes, len(frames))
+	}
+	if err := d.Close(); err != nil {
+		t.Fatalf("close error: %v", err)
+	}
+	if !bytes.HasSuffix(w.buf.Bytes(), []byte("a")) {
+		t.Errorf("got %q, want it to end with %q", w.buf.Bytes(), "a")
+	}
+}
+
+func BenchmarkEncoderWriteBuffer(b *testing.B) {
+	data := bytes.Repeat([]byte("0123456789abcdef"), 64<<10)
+
+	for _, size := range []int{0, 32 << 10} {
+		b.Run(fmt.Sprintf("size=%d", size), func(b *testing.B) {
+			w := &limitWriter{}
+			b.SetBytes(int64(len(data)))
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				w.n = 2 * len(data)
+				w.buf.Reset()
+
+				e := mustEncoder(b, w, WithWriteBufferSize(size))
+				if _, err := e.Write(data); err != nil {
+					b.Fatal(err)
+				}
+				if err := e.Close(); err != nil {
+					b.Fatal(err)
+				}
+			}
+
+			b.ReportMetric(float64(w.writes)/float64(b.N), "writes/op")
+		})
+	}
+}
+
+func TestEncoderReset(t *testing.T) {
+	var first, second bytes.Buffer
+
+	e := mustEncoder(t, &first, WithSentinel(0x42), WithDelimiterOnClose(true))
+	if _, err := e.Write([]byte("partial")); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+
+	e.Reset(&second)
+	if _, err := e.Write([]byte("Hello")); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatalf("close error: %v", err)
+	}
+
+	want, _ := Encode([]byte("Hello"), WithSentinel(0x42), WithDelimiterOnClose(true))
+	if first.Len() != 0 {
+		t.Errorf("got %v written before reset", first.Bytes())
+	}
+	if !bytes.Equal(second.Bytes(), want) {
+		t.Errorf("got %v, want %v", second.Bytes(), want)
+	}
+}
+
+func TestDecoderReset(t *testing.T) {
+	var first, second bytes.Buffer
+
+	d := mustDecoder(t, &first, WithSentinel(0x42))
+	if err := d.WriteByte(0x42 ^ 0x04); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+
+	d.Reset(&second)
+	enc, _ := Encode([]byte("Hello"), WithSentinel(0x42), WithDelimiterOnClose(true))
+	if _, err := d.Write(enc); !errors.Is(err, EOD) {
+		t.Fatalf("got %v, want %v", err, EOD)
+	}
+
+	if first.Len() != 0 {
+		t.Errorf("got %v written before reset", first.Bytes())
+	}
+	if got := second.String(); got != "Hello" {
+		t.Errorf("got %q, want %q", got, "Hello")
+	}
+	if got := d.FramesDecoded(); got != 1 {
+		t.Errorf("got %d frames, want 1", got)
+	}
+}
+
+func TestEncoderFlush(t *testing.T) {
+	var buf bytes.Buffer
+
+	e := mustEncoder(t, &buf, WithWriteBufferSize(64))
+	if _, err := e.Write([]byte("Hello\x00world")); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("got %v written before flush", buf.Bytes())
+	}
+
+	if err := e.Flush(); err != nil {
+		t.Fatalf("flush error: %v", err)
+	}
+	if got, want := buf.String(), "\x06Hello"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := e.Buffered(); got != 5 {
+		t.Errorf("got %d buffered bytes, want 5", got)
+	}
+
+	if err := e.Close(); err != nil {
+		t.Fatalf("close error: %v", err)
+	}
+	if got, want := buf.String(), "\x06Hello\x06world"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestXORBytes(t *testing.T) {
+	for n := 0; n < 20; n++ {
+		p := bytes.Repeat([]byte{0x0f}, n)
+		xorBytes(p, 0xf0)
+
+		if want := bytes.Repeat([]byte{0xff}, n); !bytes.Equal(p, want) {
+			t.Errorf("n=%d: got %v, want %v", n, p, want)
+		}
+	}
+}
+
+// shortWriter accepts at most max bytes per write without reporting an error.
+type shortWriter struct {
+	max int
+	buf bytes.Buffer
+}
+
+func (w *shortWriter) Write(p []byte) (int, error) {
+	if len(p) > w.max {
+		p = p[:w.max]
+	}
+
+	return w.buf.Write(p)
+}
+
+func TestShortWrites(t *testing.T) {
+	data := bytes.Repeat([]byte("Hello\x00world"), 100)
+	enc, _ := Encode(data, WithDelimiterOnClose(true))
+
+	w := &shortWriter{max: 3}
+	e := mustEncoder(t, w, WithDelimiterOnClose(true))
+	if _, err := e.Write(data); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatalf("close error: %v", err)
+	}
+	if !bytes.Equal(w.buf.Bytes(), enc) {
+		t.Errorf("encoded output differs")
+	}
+
+	w = &shortWriter{max: 3}
+	d := mustDecoder(t, w)
+	if _, err := d.Write(enc); err != EOD {
+		t.Fatalf("got %v, want %v", err, EOD)
+	}
+	if !bytes.Equal(w.buf.Bytes(), data) {
+		t.Errorf("decoded output differs")
+	}
+
+	// A writer that makes no progress fails
+	e = mustEncoder(t, &shortWriter{})
+	if _, err := e.Write([]byte("a\x00")); !errors.Is(err, io.ErrShortWrite) {
+		t.Errorf("got %v, want %v", err, io.ErrShortWrite)
+	}
+}
@@ -0,0 +1,112 @@
+package cobs
+
+import (
+	"io"
+	"time"
+)
+
+// Config holds the settings applied by options. Options set its fields, and
+// an Option written outside this package can do the same. The exported fields
+// are the settings of options that take plain values, which allows loading
+// them from files or flags; Options turns them into options again. The zero
+// value corresponds to passing no options. Options taking functions or
+// slices, like WithFrameHandler, have no exported field.
+type Config struct {
+	Reduced             bool          // WithReduced
+	ZPE                 bool          // WithZPE
+	ZRE                 bool          // WithZRE
+	SecondForbidden     bool          // WithSecondForbidden with Forbidden
+	Forbidden           byte          // the byte of WithSecondForbidden
+	Sentinel            byte          // WithSentinel
+	SentinelMode        SentinelMode  // WithSentinelMode
+	InsertedByte        byte          // WithInsertedByte
+	DelimiterOnClose    bool          // WithDelimiterOnClose
+	PrefixDelimiter     bool          // WithPrefixDelimiter
+	CloseUnderlying     bool          // WithCloseUnderlying
+	DiscardFirstPartial bool          // WithDiscardFirstPartial
+	SkipEmptyFrames     bool          // WithSkipEmptyFrames
+	MaxFrameSize        int           // WithMaxFrameSize
+	MaxGroupsPerFrame   int           // WithMaxGroupsPerFrame
+	MaxGroupSize        int           // WithMaxGroupSize
+	Strict              bool          // WithStrict
+	Resync              bool          // WithResync
+	FrameTimeout        time.Duration // WithFrameTimeout
+	RateLimit           int           // WithRateLimit
+	WriteBufferSize     int           // WithWriteBufferSize
+	AppendNewline       bool          // WithAppendNewline
+	AutoReset           bool          // WithAutoReset
+	EODasEOF            bool          // WithEODasEOF
+	Checksum            ChecksumKind  // WithChecksum
+	Expvar              string        // WithExpvar
+	LengthPrefix        bool          // WithLengthPrefix
+	Sequence            bool          // WithSequence
+	MinPayload          int           // WithMinPayload with Pad
+	Pad                 byte          // the pad byte of WithMinPayload
+
+	delimSeq     []byte
+	separator    []byte
+	typeRouter   func(byte) io.Writer
+	frameHandler func([]byte) error
+	keepAlive    func() error
+	histogram    *Histogram
+	metrics      Metrics
+	clock        clock
+
+	// err is the first error returned by an option
+	err error
+}
+
+// Options returns the options corresponding with cfg, which can be combined
+// with further options.
+func (cfg Config) Options() []Option {
+	opts := []Option{
+		WithReduced(cfg.Reduced),
+		WithZPE(cfg.ZPE),
+		WithZRE(cfg.ZRE),
+		WithSentinel(cfg.Sentinel),
+		WithSentinelMode(cfg.SentinelMode),
+		WithInsertedByte(cfg.InsertedByte),
+		WithDelimiterOnClose(cfg.DelimiterOnClose),
+		WithPrefixDelimiter(cfg.PrefixDelimiter),
+		WithCloseUnderlying(cfg.CloseUnderlying),
+		WithDiscardFirstPartial(cfg.DiscardFirstPartial),
+		WithSkipEmptyFrames(cfg.SkipEmptyFrames),
+		WithMaxFrameSize(cfg.MaxFrameSize),
+		WithMaxGroupsPerFrame(cfg.MaxGroupsPerFrame),
+		WithMaxGroupSize(cfg.MaxGroupSize),
+		WithStrict(cfg.Strict),
+		WithResync(cfg.Resync),
+		WithFrameTimeout(cfg.FrameTimeout),
+		WithRateLimit(cfg.RateLimit),
+		WithWriteBufferSize(cfg.WriteBufferSize),
+		WithAppendNewline(cfg.AppendNewline),
+		WithAutoReset(cfg.AutoReset),
+		WithEODasEOF(cfg.EODasEOF),
+		WithChecksum(cfg.Checksum),
+		WithLengthPrefix(cfg.LengthPrefix),
+		WithSequence(cfg.Sequence),
+		WithMinPayload(cfg.MinPayload, cfg.Pad),
+	}
+
+	if cfg.SecondForbidden {
+		opts = append(opts, WithSecondForbidden(cfg.Forbidden))
+	}
+
+	if cfg.Expvar != "" {
+		opts = append(opts, WithExpvar(cfg.Expvar))
+	}
+
+	return opts
+}
+
+// NewEncoderWithConfig returns an Encoder that writes encoded data to w,
+// configured by cfg. Errors are returned like NewEncoder.
+func NewEncoderWithConfig(w io.Writer, cfg Config) (*Encoder, error) {
+	return NewEncoder(w, cfg.Options()...)
+}
+
+// NewDecoderWithConfig returns a Decoder that writes decoded data to w,
+// configured by cfg. Errors are returned like NewDecoder.
+func NewDecoderWithConfig(w io.Writer, cfg Config) (*Decoder, error) {
+	return NewDecoder(w, cfg.Options()...)
+}
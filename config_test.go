@@ -0,0 +1,65 @@
+package cobs
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestConfig(t *testing.T) {
+	cfg := Config{
+		Reduced:          true,
+		Sentinel:         0x42,
+		DelimiterOnClose: true,
+		Checksum:         CRC8,
+		LengthPrefix:     true,
+	}
+	data := []byte("Hello\x00world\x42")
+
+	var enc bytes.Buffer
+	e, err := NewEncoderWithConfig(&enc, cfg)
+	if err != nil {
+		t.Fatalf("new encoder error: %v", err)
+	}
+	if _, err := e.Write(data); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatalf("close error: %v", err)
+	}
+
+	want, err := Encode(data, cfg.Options()...)
+	if err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+	if !bytes.Equal(enc.Bytes(), want) {
+		t.Errorf("got %v, want %v", enc.Bytes(), want)
+	}
+
+	var dec bytes.Buffer
+	d, err := NewDecoderWithConfig(&dec, cfg)
+	if err != nil {
+		t.Fatalf("new decoder error: %v", err)
+	}
+	if _, err := d.Write(enc.Bytes()); !errors.Is(err, EOD) {
+		t.Fatalf("got %v, want %v", err, EOD)
+	}
+	if !bytes.Equal(dec.Bytes(), data) {
+		t.Errorf("got %v, want %v", dec.Bytes(), data)
+	}
+}
+
+func TestConfigZero(t *testing.T) {
+	data := []byte("Hello\x00world")
+
+	got, _ := Encode(data, Config{}.Options()...)
+	want, _ := Encode(data)
+	if !bytes.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	cfg := Config{ZPE: true, Reduced: true}
+	if err := CheckOptions(cfg.Options()...); !errors.Is(err, ErrIncompatibleOptions) {
+		t.Errorf("got %v, want %v", err, ErrIncompatibleOptions)
+	}
+}
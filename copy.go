@@ -0,0 +1,156 @@
+package cobs
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// readDeadliner is implemented by readers of which a blocked read can be
+// interrupted, like net.Conn and os.File.
+type readDeadliner interface {
+	SetReadDeadline(t time.Time) error
+}
+
+// interruptRead interrupts a blocked read of r when ctx is done, if r
+// supports read deadlines. The returned function stops watching ctx, and
+// clears the read deadline again if it was set, so r remains usable.
+func interruptRead(ctx context.Context, r io.Reader) (stop func()) {
+	rd, ok := r.(readDeadliner)
+	if !ok || ctx.Done() == nil {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	interrupted := make(chan bool, 1)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = rd.SetReadDeadline(time.Now())
+			interrupted <- true
+		case <-done:
+			interrupted <- false
+		}
+	}()
+
+	return func() {
+		close(done)
+		if <-interrupted {
+			_ = rd.SetReadDeadline(time.Time{})
+		}
+	}
+}
+
+// CopyContext copies from src to dst until io.EOF, like io.Copy, and closes
+// dst, which is typically an Encoder or a Decoder. Closing ends the frame of
+// an Encoder and validates the last frame of a Decoder. The context is
+// checked between reads of src, if it is done the context error is returned
+// and dst is not closed, so a frame in progress is not ended. Data read
+// before is still written to dst. A blocked read of src is interrupted by
+// setting a read deadline in the past, if src supports it like net.Conn and
+// os.File, otherwise it is awaited. The read deadline is cleared on return.
+func CopyContext(ctx context.Context, dst io.WriteCloser, src io.Reader) (int64, error) {
+	defer interruptRead(ctx, src)()
+
+	buf := make([]byte, 32<<10)
+	var n int64
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return n, err
+		}
+
+		m, rerr := src.Read(buf)
+
+		if m > 0 {
+			w, err := dst.Write(buf[:m])
+			n += int64(w)
+			if err != nil {
+				return n, err
+			}
+		}
+
+		if rerr == io.EOF {
+			return n, dst.Close()
+		}
+		if rerr != nil {
+			// The read was interrupted
+			if err := ctx.Err(); err != nil {
+				return n, err
+			}
+
+			return n, rerr
+		}
+	}
+}
+
+// countWriter counts the bytes written to w.
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+
+	return n, err
+}
+
+// CopyFramesContext decodes the frames read from src until io.EOF and writes
+// their decoded data to dst. It returns the number of bytes written and the
+// first error encountered. A last frame without delimiter is ended as by
+// Decoder.Close. The context is checked between reads of src, if it is done
+// the context error is returned once the data read before is decoded. When
+// this happens in the middle of a frame, it is wrapped in a FrameError with
+// the offset reached. A blocked read of src is interrupted like by
+// CopyContext.
+func CopyFramesContext(ctx context.Context, dst io.Writer, src io.Reader, opts ...Option) (int64, error) {
+	defer interruptRead(ctx, src)()
+
+	cw := &countWriter{w: dst}
+	d := newDecoder(cw, append(opts[:len(opts):len(opts)], WithEODasEOF(false), WithAutoReset(false))...)
+	buf := make([]byte, 32<<10)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return cw.n, d.cancel(err)
+		}
+
+		n, rerr := src.Read(buf)
+
+		for p := buf[:n]; len(p) > 0; {
+			m, err := d.Write(p)
+			if err != nil && err != EOD {
+				return cw.n, err
+			}
+			if err == nil {
+				break
+			}
+
+			p = p[m+1:]
+		}
+
+		if rerr == io.EOF {
+			return cw.n, d.Close()
+		}
+		if rerr != nil {
+			// The read was interrupted
+			if err := ctx.Err(); err != nil {
+				return cw.n, d.cancel(err)
+			}
+
+			return cw.n, rerr
+		}
+	}
+}
+
+// cancel returns err for a cancelled copy, as FrameError if a frame is in
+// progress.
+func (d *Decoder) cancel(err error) error {
+	if d.offset > 0 {
+		return &FrameError{Err: err, Offset: d.offset, Frame: d.frames, InputOffset: d.start + int64(d.offset)}
+	}
+
+	return err
+}
@@ -0,0 +1,179 @@
+package cobs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// cancelReader cancels a context once all data is read.
+type cancelReader struct {
+	r      io.Reader
+	cancel context.CancelFunc
+}
+
+func (c *cancelReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	if err == io.EOF {
+		c.cancel()
+		// Keep the reader blocked in a real stream
+		err = nil
+	}
+
+	return n, err
+}
+
+func TestCopyFramesContext(t *testing.T) {
+	var out bytes.Buffer
+	n, err := CopyFramesContext(context.Background(), &out,
+		bytes.NewReader([]byte("\x02a\x00\x00\x03bc\x00\x02d")))
+	if err != nil {
+		t.Fatalf("copy error: %v", err)
+	}
+	if n != 4 || out.String() != "abcd" {
+		t.Errorf("got %d, %q", n, out.String())
+	}
+
+	n, err = CopyFramesContext(context.Background(), &out,
+		bytes.NewReader([]byte("\x02a\x00\x05ab\x00")))
+	if !errors.Is(err, ErrUnexpectedEOD) {
+		t.Errorf("got %v, want %v", err, ErrUnexpectedEOD)
+	}
+	if n != 3 {
+		t.Errorf("got %d, want 3", n)
+	}
+}
+
+func TestCopyFramesContextCancel(t *testing.T) {
+	testCases := []struct {
+		name   string
+		input  []byte
+		offset int
+	}{
+		{
+			name:  "Between frames",
+			input: []byte("\x02a\x00\x03bc\x00"),
+		},
+		{
+			name:   "Half-read frame",
+			input:  []byte("\x02a\x00\x04bc"),
+			offset: 3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var out bytes.Buffer
+			r := &cancelReader{r: bytes.NewReader(tc.input), cancel: cancel}
+
+			_, err := CopyFramesContext(ctx, &out, r)
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("got %v, want %v", err, context.Canceled)
+			}
+
+			var fe *FrameError
+			if tc.offset == 0 && errors.As(err, &fe) {
+				t.Errorf("got %v, want no FrameError", err)
+			}
+			if tc.offset != 0 && (!errors.As(err, &fe) || fe.Offset != tc.offset) {
+				t.Errorf("got %v, want FrameError at offset %d", err, tc.offset)
+			}
+		})
+	}
+}
+
+func TestCopyContext(t *testing.T) {
+	var out bytes.Buffer
+	e := mustEncoder(t, &out, WithDelimiterOnClose(true))
+
+	n, err := CopyContext(context.Background(), e, bytes.NewReader([]byte("ab\x00c")))
+	if err != nil {
+		t.Fatalf("copy error: %v", err)
+	}
+	if n != 4 || out.String() != "\x03ab\x02c\x00" {
+		t.Errorf("got %d, %q", n, out.String())
+	}
+}
+
+func TestCopyContextInterrupt(t *testing.T) {
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	defer c2.Close()
+
+	go func() {
+		_, _ = c2.Write([]byte("\x02a\x00\x03b"))
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// Both block reading c1 until the context is done
+	var out bytes.Buffer
+	if _, err := CopyFramesContext(ctx, &out, c1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want %v", err, context.DeadlineExceeded)
+	}
+	if out.String() != "ab" {
+		t.Errorf("got %q, want %q", out.String(), "ab")
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := CopyContext(ctx, mustDecoder(t, io.Discard), c1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// The read deadline set to interrupt is cleared again
+	go func() {
+		_, _ = c2.Write([]byte("c"))
+	}()
+	buf := make([]byte, 1)
+	if _, err := c1.Read(buf); err != nil || buf[0] != 'c' {
+		t.Errorf("read after copy got %q, %v", buf, err)
+	}
+}
+
+// dataCancelReader cancels a context in the read that returns its data.
+type dataCancelReader struct {
+	data   []byte
+	cancel context.CancelFunc
+}
+
+func (r *dataCancelReader) Read(p []byte) (int, error) {
+	r.cancel()
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+
+	return n, nil
+}
+
+func TestCopyContextFlush(t *testing.T) {
+	// Data read when the context is done is still written
+	ctx, cancel := context.WithCancel(context.Background())
+	var out bytes.Buffer
+	r := &dataCancelReader{data: []byte("\x02a\x00\x02b\x00"), cancel: cancel}
+	if _, err := CopyFramesContext(ctx, &out, r); !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want %v", err, context.Canceled)
+	}
+	if out.String() != "ab" {
+		t.Errorf("got %q, want %q", out.String(), "ab")
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	out.Reset()
+	r = &dataCancelReader{data: []byte("\x02a\x00\x02b\x00"), cancel: cancel}
+	n, err := CopyContext(ctx, mustDecoder(t, &out, WithAutoReset(true)), r)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want %v", err, context.Canceled)
+	}
+	if n != 6 || out.String() != "ab" {
+		t.Errorf("got %d, %q, want 6, %q", n, out.String(), "ab")
+	}
+}
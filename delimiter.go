@@ -0,0 +1,23 @@
+package cobs
+
+import "errors"
+
+// ErrInvalidDelimiter means that the bytes following the first byte of a
+// delimiter sequence do not match the sequence.
+var ErrInvalidDelimiter = errors.New("invalid delimiter")
+
+// delimTail checks c against the rest of the delimiter sequence, after the
+// sentinel that starts it.
+func (d *Decoder) delimTail(c byte) error {
+	want := d.delim[len(d.delim)-d.delimRest]
+	d.delimRest--
+	d.start = int64(d.stats.BytesIn)
+
+	if c != want {
+		d.delimRest = 0
+
+		return d.frameError(ErrInvalidDelimiter, 0)
+	}
+
+	return nil
+}
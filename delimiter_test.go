@@ -0,0 +1,66 @@
+package cobs
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestDelimiterSequence(t *testing.T) {
+	crlf := WithDelimiterSequence([]byte("\r\n"))
+	frames := [][]byte{
+		[]byte("a\r\nb"),
+		{},
+		[]byte("\r\r\n\n"),
+	}
+
+	enc, err := EncodeFrames(frames, crlf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := bytes.Count(enc, []byte("\r\n")); n != len(frames) {
+		t.Fatalf("got %d delimiters in %q, want %d", n, enc, len(frames))
+	}
+
+	if max := MaxEncodedLen(4, crlf, WithDelimiterOnClose(true)); len(enc) > 3*max {
+		t.Errorf("encoded length %d exceeds %d", len(enc), 3*max)
+	}
+
+	dec, err := DecodeFrames(enc, crlf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(dec) != len(frames) {
+		t.Fatalf("got %d frames, want %d", len(dec), len(frames))
+	}
+
+	for i := range frames {
+		if !bytes.Equal(dec[i], frames[i]) {
+			t.Errorf("frame %d: got %q, want %q", i, dec[i], frames[i])
+		}
+	}
+}
+
+func TestDelimiterSequenceInvalid(t *testing.T) {
+	var fe *FrameError
+	crlf := WithDelimiterSequence([]byte("\r\n"))
+
+	enc, err := Encode([]byte("a"), crlf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = DecodeFrames(append(enc, "\rx"...), crlf)
+	if !errors.Is(err, ErrInvalidDelimiter) || !errors.As(err, &fe) {
+		t.Fatalf("got %v, want ErrInvalidDelimiter", err)
+	}
+}
+
+func TestDelimiterSequenceSentinel(t *testing.T) {
+	_, err := Encode([]byte(nil), WithDelimiterSequence([]byte("\r\n")), WithSentinel(0x0a))
+	if !errors.Is(err, ErrIncompatibleOptions) {
+		t.Errorf("got %v, want ErrIncompatibleOptions", err)
+	}
+}
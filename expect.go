@@ -0,0 +1,55 @@
+package cobs
+
+import "fmt"
+
+// A MismatchError records the first offset at which decoded data differs
+// from the expected payload. Got and Want hold the bytes at Offset, or -1 if
+// the data ends before Offset.
+type MismatchError struct {
+	Offset int
+	Got    int
+	Want   int
+}
+
+func (e *MismatchError) Error() string {
+	return fmt.Sprintf("mismatch at offset %d: got %s, want %s",
+		e.Offset, mismatchByte(e.Got), mismatchByte(e.Want))
+}
+
+func mismatchByte(b int) string {
+	if b < 0 {
+		return "end of data"
+	}
+
+	return fmt.Sprintf("0x%02x", b)
+}
+
+// DecodeExpect decodes a single frame, optionally terminated by a delimiter,
+// and compares it with expected. Errors of decoding are returned as is, a
+// difference results in a MismatchError for the first differing byte.
+func DecodeExpect(encoded, expected []byte, opts ...Option) error {
+	if n := len(encoded); n > 0 && encoded[n-1] == newConfig(opts).Sentinel {
+		encoded = encoded[:n-1]
+	}
+
+	dec, err := Decode(encoded, opts...)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < len(dec) || i < len(expected); i++ {
+		got, want := -1, -1
+		if i < len(dec) {
+			got = int(dec[i])
+		}
+		if i < len(expected) {
+			want = int(expected[i])
+		}
+
+		if got != want {
+			return &MismatchError{Offset: i, Got: got, Want: want}
+		}
+	}
+
+	return nil
+}
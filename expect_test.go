@@ -0,0 +1,69 @@
+package cobs
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDecodeExpect(t *testing.T) {
+	testCases := []struct {
+		name     string
+		enc      []byte
+		expected []byte
+		err      *MismatchError
+	}{
+		{
+			name:     "Match",
+			enc:      []byte("\x0612345\x056789"),
+			expected: []byte("12345\x006789"),
+		},
+		{
+			name:     "Match with delimiter",
+			enc:      []byte("\x0612345\x00"),
+			expected: []byte("12345"),
+		},
+		{
+			name:     "Different byte",
+			enc:      []byte("\x0612345\x056789"),
+			expected: []byte("12345\x006889"),
+			err:      &MismatchError{Offset: 7, Got: '7', Want: '8'},
+		},
+		{
+			name:     "Short",
+			enc:      []byte("\x0612345"),
+			expected: []byte("12345\x00"),
+			err:      &MismatchError{Offset: 5, Got: -1, Want: 0x00},
+		},
+		{
+			name:     "Long",
+			enc:      []byte("\x0612345\x01"),
+			expected: []byte("12345"),
+			err:      &MismatchError{Offset: 5, Got: 0x00, Want: -1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := DecodeExpect(tc.enc, tc.expected)
+			if tc.err == nil {
+				if err != nil {
+					t.Errorf("got %v, want nil", err)
+				}
+				return
+			}
+
+			var me *MismatchError
+			if !errors.As(err, &me) {
+				t.Fatalf("got %v, want *MismatchError", err)
+			}
+			if *me != *tc.err {
+				t.Errorf("got %v, want %v", me, tc.err)
+			}
+		})
+	}
+
+	err := DecodeExpect([]byte("\x0612"), []byte("12"))
+	if !errors.Is(err, ErrIncompleteFrame) {
+		t.Errorf("got %v, want %v", err, ErrIncompleteFrame)
+	}
+}
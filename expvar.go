@@ -0,0 +1,46 @@
+package cobs
+
+import (
+	"expvar"
+	"sync"
+)
+
+// expvarMu serializes looking up and publishing variables.
+var expvarMu sync.Mutex
+
+// decoderVars holds the published counters of a Decoder.
+type decoderVars struct {
+	frames *expvar.Int
+	bytes  *expvar.Int
+	errors *expvar.Int
+}
+
+func newDecoderVars(prefix string) *decoderVars {
+	expvarMu.Lock()
+	defer expvarMu.Unlock()
+
+	return &decoderVars{
+		frames: publishInt(prefix + ".frames"),
+		bytes:  publishInt(prefix + ".bytes"),
+		errors: publishInt(prefix + ".errors"),
+	}
+}
+
+// publishInt returns the integer variable name, publishing it if needed.
+// Decoders using the same prefix share their counters.
+func publishInt(name string) *expvar.Int {
+	v := expvar.Get(name)
+	if v == nil {
+		i := new(expvar.Int)
+		expvar.Publish(name, i)
+
+		return i
+	}
+
+	if i, ok := v.(*expvar.Int); ok {
+		return i
+	}
+
+	// The name is taken by another type, count without publishing
+	return new(expvar.Int)
+}
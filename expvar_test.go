@@ -0,0 +1,61 @@
+package cobs
+
+import (
+	"expvar"
+	"io"
+	"testing"
+)
+
+// expvarValue returns the value of the published counter name, or 0 if it is
+// not published yet.
+func expvarValue(name string) int64 {
+	if v, ok := expvar.Get(name).(*expvar.Int); ok {
+		return v.Value()
+	}
+
+	return 0
+}
+
+func TestExpvar(t *testing.T) {
+	// The counters are process global, only count what this test adds
+	names := []string{"cobs_test.frames", "cobs_test.bytes", "cobs_test.errors"}
+	before := make(map[string]int64)
+	for _, name := range names {
+		before[name] = expvarValue(name)
+	}
+
+	d := mustDecoder(t, io.Discard, WithExpvar("cobs_test"))
+	stream := []byte("\x0612345\x00\x01\x00\x03a\x00")
+
+	for i := 0; i < 2; i++ {
+		n, err := d.Write(stream)
+		if err != EOD {
+			t.Fatalf("frame %d got %v, want %v", i, err, EOD)
+		}
+		stream = stream[n+1:]
+	}
+	if _, err := d.Write(stream); err == nil {
+		t.Fatal("malformed frame error missing")
+	}
+
+	// A second decoder shares the counters
+	d = mustDecoder(t, io.Discard, WithExpvar("cobs_test"))
+	if _, err := d.Write([]byte("\x02a\x00")); err != EOD {
+		t.Fatalf("got %v, want %v", err, EOD)
+	}
+
+	want := map[string]int64{
+		"cobs_test.frames": 3,
+		"cobs_test.bytes":  6,
+		"cobs_test.errors": 1,
+	}
+	for name, value := range want {
+		if expvar.Get(name) == nil {
+			t.Errorf("%s is not published", name)
+			continue
+		}
+		if got := expvarValue(name) - before[name]; got != value {
+			t.Errorf("%s got %d more, want %d", name, got, value)
+		}
+	}
+}
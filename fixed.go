@@ -0,0 +1,213 @@
+package cobs
+
+import (
+	"io"
+	"sync"
+)
+
+// MaxEncodedLen returns the maximum length of the encoding of n bytes with
+// the given options. Delimiters are only included with WithDelimiterOnClose
+// or WithPrefixDelimiter. A checksum, length prefix and padding are included
+// as well.
+func MaxEncodedLen(n int, opts ...Option) int {
+	c := newConfig(opts)
+
+	if n < c.MinPayload {
+		n = c.MinPayload
+	}
+
+	if c.LengthPrefix {
+		for v := uint64(n); v >= 0x80; v >>= 7 {
+			n++
+		}
+		n++
+	}
+
+	n += c.Checksum.size()
+
+	if c.Sequence {
+		n++
+	}
+
+	groupMax := int(maxCode(c.ZPE)) - 1
+	switch {
+	case c.MaxGroupSize > 0:
+		groupMax = c.MaxGroupSize
+	case c.ZRE:
+		groupMax = zreMaxCode - 1
+	case c.SecondForbidden:
+		groupMax = secondMax
+	}
+
+	n += n/groupMax + 1
+
+	delim := 1
+	if len(c.delimSeq) > 0 {
+		delim = len(c.delimSeq)
+	}
+
+	if c.DelimiterOnClose {
+		n += delim
+	}
+
+	if c.PrefixDelimiter {
+		n += delim
+	}
+
+	return n
+}
+
+// MaxDecodedLen returns the maximum length of the decoding of n encoded
+// bytes with the given options. With WithZPE a single code byte decodes to a
+// pair of zeros, so the decoding may be twice as long. With WithZRE it decodes
+// to a run of up to 48 zeros. The newline of WithAppendNewline and the
+// separator of WithFrameSeparator are included for each frame, which is a
+// single one unless WithAutoReset is set.
+func MaxDecodedLen(n int, opts ...Option) int {
+	c := newConfig(opts)
+
+	m := n
+	switch {
+	case c.ZPE:
+		m = 2 * n
+	case c.ZRE:
+		m = zreMaxRun * n
+	}
+
+	trailer := len(c.separator)
+	if c.AppendNewline {
+		trailer++
+	}
+
+	// Each frame ends at a byte of its own
+	frames := 1
+	if c.AutoReset {
+		frames = n
+	}
+
+	return m + frames*trailer
+}
+
+// A fixedWriter appends to buf without growing it, unless grow is set.
+type fixedWriter struct {
+	buf  []byte
+	grow bool
+}
+
+func (w *fixedWriter) Write(p []byte) (int, error) {
+	if w.grow {
+		w.buf = append(w.buf, p...)
+		return len(p), nil
+	}
+
+	n := copy(w.buf[len(w.buf):cap(w.buf)], p)
+	w.buf = w.buf[:len(w.buf)+n]
+
+	if n < len(p) {
+		return n, io.ErrShortBuffer
+	}
+
+	return n, nil
+}
+
+// A fixedEncoder is an Encoder writing to a fixed buffer.
+type fixedEncoder struct {
+	Encoder
+	w fixedWriter
+}
+
+// A fixedDecoder is a Decoder writing to a fixed buffer.
+type fixedDecoder struct {
+	Decoder
+	w fixedWriter
+}
+
+var (
+	encoderPool = sync.Pool{
+		New: func() any {
+			return new(fixedEncoder)
+		},
+	}
+	decoderPool = sync.Pool{
+		New: func() any {
+			return new(fixedDecoder)
+		},
+	}
+)
+
+// EncodeTo encodes src into dst and returns the number of bytes written to
+// dst, which holds the same bytes Encode returns. If dst is too small, the
+// bytes that fit are written and io.ErrShortBuffer is returned. A dst of
+// MaxEncodedLen(len(src), opts...) bytes is large enough. EncodeTo does not
+// allocate.
+func EncodeTo(dst, src []byte, opts ...Option) (int, error) {
+	buf, err := encodeTo(dst[:0], false, src, opts)
+
+	return len(buf), err
+}
+
+// AppendEncode appends the encoding of src to dst and returns the extended
+// buffer, like Encode. It only allocates if dst has to grow.
+func AppendEncode(dst, src []byte, opts ...Option) ([]byte, error) {
+	return encodeTo(dst, true, src, opts)
+}
+
+// encodeTo appends the encoding of src to dst using a pooled Encoder.
+func encodeTo(dst []byte, grow bool, src []byte, opts []Option) ([]byte, error) {
+	c := newConfig(opts)
+	c.RateLimit = 0
+
+	fe := encoderPool.Get().(*fixedEncoder)
+	fe.w.buf = dst
+	fe.w.grow = grow
+	fe.init(&fe.w, c)
+
+	_, err := fe.Write(src)
+	if err == nil {
+		err = fe.Close()
+	}
+
+	dst = fe.w.buf
+	fe.w.buf = nil
+	fe.init(nil, Config{})
+	encoderPool.Put(fe)
+
+	return dst, err
+}
+
+// DecodeInto decodes src into dst and returns the number of bytes written to
+// dst, which holds the same bytes Decode returns. If dst is too small, the
+// bytes that fit are written and io.ErrShortBuffer is returned. A dst of
+// MaxDecodedLen(len(src), opts...) bytes is large enough. DecodeInto does
+// not allocate.
+func DecodeInto(dst, src []byte, opts ...Option) (int, error) {
+	buf, err := decodeTo(dst[:0], false, src, opts)
+
+	return len(buf), err
+}
+
+// AppendDecode appends the decoding of src to dst and returns the extended
+// buffer, like Decode. It only allocates if dst has to grow.
+func AppendDecode(dst, src []byte, opts ...Option) ([]byte, error) {
+	return decodeTo(dst, true, src, opts)
+}
+
+// decodeTo appends the decoding of src to dst using a pooled Decoder.
+func decodeTo(dst []byte, grow bool, src []byte, opts []Option) ([]byte, error) {
+	fd := decoderPool.Get().(*fixedDecoder)
+	fd.w.buf = dst
+	fd.w.grow = grow
+	fd.init(&fd.w, newConfig(opts))
+
+	_, err := fd.Write(src)
+	if err == nil {
+		err = fd.Close()
+	}
+
+	dst = fd.w.buf
+	fd.w.buf = nil
+	fd.init(nil, Config{})
+	decoderPool.Put(fd)
+
+	return dst, err
+}
@@ -0,0 +1,224 @@
+package cobs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestEncodeTo(t *testing.T) {
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dst := make([]byte, MaxEncodedLen(len(tc.dec)))
+
+			n, err := EncodeTo(dst, tc.dec)
+			if err != nil {
+				t.Fatalf("encode error: %v", err)
+			}
+			if !bytes.Equal(dst[:n], tc.enc) {
+				t.Errorf("got %v, want %v", dst[:n], tc.enc)
+			}
+		})
+	}
+
+	_, err := EncodeTo(make([]byte, 3), []byte("12345"))
+	if !errors.Is(err, io.ErrShortBuffer) {
+		t.Errorf("got %v, want %v", err, io.ErrShortBuffer)
+	}
+}
+
+func TestDecodeInto(t *testing.T) {
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dst := make([]byte, MaxDecodedLen(len(tc.enc)))
+
+			n, err := DecodeInto(dst, tc.enc)
+			if err != nil {
+				t.Fatalf("decode error: %v", err)
+			}
+			if !bytes.Equal(dst[:n], tc.dec) {
+				t.Errorf("got %v, want %v", dst[:n], tc.dec)
+			}
+		})
+	}
+
+	_, err := DecodeInto(make([]byte, 3), []byte("\x0612345"))
+	if !errors.Is(err, io.ErrShortBuffer) {
+		t.Errorf("got %v, want %v", err, io.ErrShortBuffer)
+	}
+}
+
+func TestEncodeDecodeIntoOptions(t *testing.T) {
+	opts := []Option{WithReduced(true), WithSentinel(0x42), WithDelimiterOnClose(true)}
+	data := bytes.Repeat([]byte("0123456789\x00\x42"), 50)
+
+	want, err := Encode(data, opts...)
+	if err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+
+	enc := make([]byte, MaxEncodedLen(len(data))+1)
+	n, err := EncodeTo(enc, data, opts...)
+	if err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+	if !bytes.Equal(enc[:n], want) {
+		t.Errorf("got %v, want %v", enc[:n], want)
+	}
+
+	// Decoding stops at the delimiter
+	dec := make([]byte, MaxDecodedLen(n))
+	m, err := DecodeInto(dec, enc[:n-1], opts...)
+	if err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if !bytes.Equal(dec[:m], data) {
+		t.Errorf("got %v, want %v", dec[:m], data)
+	}
+}
+
+func TestEncodeDecodeIntoAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocations are not reliable with the race detector")
+	}
+
+	data := bytes.Repeat([]byte("0123456789\x00"), 100)
+	enc := make([]byte, MaxEncodedLen(len(data)))
+	dec := make([]byte, MaxDecodedLen(len(enc)))
+	opts := []Option{WithSentinel(0x42)}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		n, err := EncodeTo(enc, data, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := DecodeInto(dec, enc[:n], opts...); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("got %v allocs, want 0", allocs)
+	}
+}
+
+func TestAppendEncodeDecode(t *testing.T) {
+	prefix := []byte("prefix")
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			enc, err := AppendEncode(prefix[:len(prefix):len(prefix)], tc.dec)
+			if err != nil {
+				t.Fatalf("encode error: %v", err)
+			}
+			if want := append(prefix[:len(prefix):len(prefix)], tc.enc...); !bytes.Equal(enc, want) {
+				t.Errorf("got %v, want %v", enc, want)
+			}
+
+			dec, err := AppendDecode(prefix[:len(prefix):len(prefix)], tc.enc)
+			if err != nil {
+				t.Fatalf("decode error: %v", err)
+			}
+			if want := append(prefix[:len(prefix):len(prefix)], tc.dec...); !bytes.Equal(dec, want) {
+				t.Errorf("got %v, want %v", dec, want)
+			}
+		})
+	}
+}
+
+func TestAppendEncodeAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocations are not reliable with the race detector")
+	}
+
+	data := bytes.Repeat([]byte("0123456789\x00"), 100)
+	enc := make([]byte, 0, MaxEncodedLen(len(data)))
+	dec := make([]byte, 0, len(data))
+
+	allocs := testing.AllocsPerRun(100, func() {
+		enc, _ = AppendEncode(enc[:0], data)
+		dec, _ = AppendDecode(dec[:0], enc)
+	})
+	if allocs > 0 {
+		t.Errorf("got %v allocations, want 0", allocs)
+	}
+}
+
+func TestMaxEncodedLen(t *testing.T) {
+	optSets := map[string][]Option{
+		"default":   nil,
+		"reduced":   {WithReduced(true)},
+		"zpe":       {WithZPE(true)},
+		"second":    {WithSecondForbidden(0xff)},
+		"delimiter": {WithDelimiterOnClose(true), WithPrefixDelimiter(true)},
+		"checksum":  {WithChecksum(CRC16CCITT)},
+		"length":    {WithLengthPrefix(true), WithMinPayload(300, 0x01)},
+	}
+
+	for name, opts := range optSets {
+		t.Run(name, func(t *testing.T) {
+			for _, n := range []int{0, 1, 125, 126, 127, 222, 223, 224, 253, 254, 255, 508, 1000} {
+				data := bytes.Repeat([]byte{0x01}, n)
+
+				enc, err := Encode(data, opts...)
+				if err != nil {
+					t.Fatalf("encode error: %v", err)
+				}
+				if max := MaxEncodedLen(n, opts...); len(enc) > max {
+					t.Errorf("n=%d: got %d bytes, want at most %d", n, len(enc), max)
+				}
+
+				dec, err := Decode(bytes.TrimSuffix(enc, []byte{Delimiter}), opts...)
+				if len(dec) > MaxDecodedLen(len(enc), opts...) {
+					t.Errorf("n=%d: decoded %d bytes, want at most %d (%v)", n, len(dec), MaxDecodedLen(len(enc), opts...), err)
+				}
+			}
+		})
+	}
+
+	if got := MaxDecodedLen(4, WithZPE(true)); got != 8 {
+		t.Errorf("got %d, want 8", got)
+	}
+}
+
+func TestMaxDecodedLenTrailer(t *testing.T) {
+	testCases := []struct {
+		name string
+		enc  []byte
+		opts []Option
+		want string
+	}{
+		{
+			name: "Separator",
+			enc:  []byte("\x02a"),
+			opts: []Option{WithFrameSeparator([]byte("----"))},
+			want: "a----",
+		},
+		{
+			name: "Newline and separator",
+			enc:  []byte("\x02a"),
+			opts: []Option{WithAppendNewline(true), WithFrameSeparator([]byte(";"))},
+			want: "a\n;",
+		},
+		{
+			name: "Frames",
+			enc:  []byte("\x02a\x00\x01\x00\x00\x02b"),
+			opts: []Option{WithAutoReset(true), WithAppendNewline(true)},
+			want: "a\n\n\nb\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dst := make([]byte, MaxDecodedLen(len(tc.enc), tc.opts...))
+
+			n, err := DecodeInto(dst, tc.enc, tc.opts...)
+			if err != nil {
+				t.Fatalf("decode error: %v", err)
+			}
+			if string(dst[:n]) != tc.want {
+				t.Errorf("got %q, want %q", dst[:n], tc.want)
+			}
+		})
+	}
+}
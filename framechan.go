@@ -0,0 +1,89 @@
+package cobs
+
+import (
+	"context"
+	"io"
+)
+
+// A Frame is a decoded frame delivered by FrameChan, or the error that ended
+// decoding, in which case Data is nil. The Data of an empty frame is not nil.
+// With WithKeepAlive, KeepAlive reports a delimiter that is not preceded by a
+// frame, Data is nil then as well.
+type Frame struct {
+	Data      []byte
+	Err       error
+	KeepAlive bool
+}
+
+// FrameChan decodes the frames read from r in a goroutine and delivers them
+// over the returned channel, which is closed when decoding ends. A last frame
+// that is not terminated is ended as by Decoder.Close. Errors of decoding and
+// of r are delivered as a last Frame, io.EOF ends decoding without error. If
+// ctx is done, the channel is closed without delivering further frames. A
+// blocked read of r is interrupted like by CopyContext, if r supports read
+// deadlines, otherwise the goroutine ends once the read returns.
+func FrameChan(ctx context.Context, r io.Reader, opts ...Option) <-chan Frame {
+	ch := make(chan Frame)
+
+	send := func(f Frame) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		select {
+		case ch <- f:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	handler := func(frame []byte) error {
+		return send(Frame{Data: append([]byte{}, frame...)})
+	}
+
+	opts = append(opts[:len(opts):len(opts)], WithFrameHandler(handler))
+
+	// Keep-alives are delivered after calling the function of the option
+	if keepAlive := newConfig(opts).keepAlive; keepAlive != nil {
+		opts = append(opts, WithKeepAlive(func() error {
+			if err := keepAlive(); err != nil {
+				return err
+			}
+
+			return send(Frame{KeepAlive: true})
+		}))
+	}
+
+	go func() {
+		defer close(ch)
+		defer interruptRead(ctx, r)()
+
+		d := newDecoder(nil, opts...)
+		buf := make([]byte, 4096)
+
+		for ctx.Err() == nil {
+			n, err := r.Read(buf)
+
+			if _, derr := d.Write(buf[:n]); derr != nil {
+				if ctx.Err() == nil {
+					_ = send(Frame{Err: derr})
+				}
+				return
+			}
+
+			switch {
+			case err == io.EOF:
+				if cerr := d.Close(); cerr != nil && ctx.Err() == nil {
+					_ = send(Frame{Err: cerr})
+				}
+				return
+			case err != nil:
+				_ = send(Frame{Err: err})
+				return
+			}
+		}
+	}()
+
+	return ch
+}
@@ -0,0 +1,107 @@
+package cobs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+)
+
+func TestFrameChan(t *testing.T) {
+	input := "\x02a\x00\x01\x00\x03bc\x00\x05d"
+
+	var got []Frame
+	for f := range FrameChan(context.Background(), bytes.NewReader([]byte(input))) {
+		got = append(got, f)
+	}
+
+	want := []string{"a", "", "bc"}
+	if len(got) != len(want)+1 {
+		t.Fatalf("got %d frames, want %d", len(got), len(want)+1)
+	}
+	for i, w := range want {
+		if got[i].Err != nil || string(got[i].Data) != w {
+			t.Errorf("frame %d: got %q, %v, want %q", i, got[i].Data, got[i].Err, w)
+		}
+	}
+	if got[1].Data == nil {
+		t.Error("got nil data for an empty frame")
+	}
+	if last := got[len(want)]; !errors.Is(last.Err, ErrIncompleteFrame) || last.Data != nil {
+		t.Errorf("got %q, %v, want %v", last.Data, last.Err, ErrIncompleteFrame)
+	}
+}
+
+func TestFrameChanCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	pr, pw := io.Pipe()
+
+	ch := FrameChan(ctx, pr)
+
+	go func() {
+		_, _ = pw.Write([]byte("\x02a\x00"))
+	}()
+
+	if f := <-ch; string(f.Data) != "a" {
+		t.Fatalf("got %q, %v, want %q", f.Data, f.Err, "a")
+	}
+
+	// A frame is not delivered after cancellation
+	cancel()
+	go func() {
+		_, _ = pw.Write([]byte("\x02b\x00"))
+		pw.Close()
+	}()
+
+	for f := range ch {
+		t.Errorf("got %q, %v after cancel", f.Data, f.Err)
+	}
+}
+
+func TestFrameChanKeepAlive(t *testing.T) {
+	keepAlives := 0
+	opt := WithKeepAlive(func() error {
+		keepAlives++
+
+		return nil
+	})
+
+	var got []Frame
+	for f := range FrameChan(context.Background(), bytes.NewReader([]byte("\x00\x01\x00")), opt) {
+		got = append(got, f)
+	}
+
+	if len(got) != 2 || !got[0].KeepAlive || got[1].KeepAlive || got[1].Err != nil {
+		t.Errorf("got %+v, want a keep-alive and an empty frame", got)
+	}
+	if keepAlives != 1 {
+		t.Errorf("got %d keep-alives, want 1", keepAlives)
+	}
+}
+
+func TestFrameChanInterrupt(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	defer c2.Close()
+
+	ch := FrameChan(ctx, c1)
+
+	// The blocked read is interrupted, without closing the connection
+	cancel()
+	for f := range ch {
+		t.Errorf("got %q, %v after cancel", f.Data, f.Err)
+	}
+
+	// The read deadline is cleared again
+	go func() {
+		_, _ = c2.Write([]byte("x"))
+	}()
+
+	buf := make([]byte, 1)
+	if _, err := c1.Read(buf); err != nil {
+		t.Errorf("got %v after interrupt, want nil", err)
+	}
+}
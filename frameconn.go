@@ -0,0 +1,67 @@
+package cobs
+
+import (
+	"net"
+	"time"
+)
+
+// A FrameConn exchanges messages as delimiter terminated frames over a
+// net.Conn. Unlike a Transport, malformed frames are dropped, so ReadFrame
+// only returns complete messages or errors of the connection.
+type FrameConn struct {
+	conn net.Conn
+	t    *Transport
+}
+
+// NewFrameConn returns a FrameConn that sends and receives messages over
+// conn. The options apply to both directions, WithResync is always enabled.
+func NewFrameConn(conn net.Conn, opts ...Option) *FrameConn {
+	return &FrameConn{
+		conn: conn,
+		t:    NewTransport(conn, append(opts[:len(opts):len(opts)], WithResync(true))...),
+	}
+}
+
+// ReadFrame reads and returns the next message. Malformed frames are skipped.
+// A frame in progress is kept when a deadline expires, so ReadFrame can be
+// called again to continue with it. If the connection ends in the middle of a
+// frame io.ErrUnexpectedEOF is returned, otherwise io.EOF.
+func (c *FrameConn) ReadFrame() ([]byte, error) {
+	return c.t.Recv()
+}
+
+// WriteFrame encodes p and writes it as a single frame. It is safe to call
+// WriteFrame concurrently with ReadFrame and with other calls of WriteFrame.
+func (c *FrameConn) WriteFrame(p []byte) error {
+	return c.t.Send(p)
+}
+
+// Close closes the connection.
+func (c *FrameConn) Close() error {
+	return c.conn.Close()
+}
+
+// LocalAddr returns the local network address of the connection.
+func (c *FrameConn) LocalAddr() net.Addr {
+	return c.conn.LocalAddr()
+}
+
+// RemoteAddr returns the remote network address of the connection.
+func (c *FrameConn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+// SetDeadline sets the read and write deadlines of the connection.
+func (c *FrameConn) SetDeadline(t time.Time) error {
+	return c.conn.SetDeadline(t)
+}
+
+// SetReadDeadline sets the deadline for ReadFrame.
+func (c *FrameConn) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
+// SetWriteDeadline sets the deadline for WriteFrame.
+func (c *FrameConn) SetWriteDeadline(t time.Time) error {
+	return c.conn.SetWriteDeadline(t)
+}
@@ -0,0 +1,78 @@
+package cobs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestFrameConn(t *testing.T) {
+	c1, c2 := net.Pipe()
+	fc := NewFrameConn(c1)
+	defer fc.Close()
+	defer c2.Close()
+
+	go func() {
+		_, _ = c2.Write([]byte("\x02a\x00" + // valid
+			"\x05ab\x00" + // unexpected delimiter, dropped
+			"\x03bc"))
+		_, _ = c2.Write([]byte("\x00\x02d"))
+		c2.Close()
+	}()
+
+	for _, want := range []string{"a", "bc"} {
+		msg, err := fc.ReadFrame()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(msg) != want {
+			t.Errorf("got %q, want %q", msg, want)
+		}
+	}
+
+	if _, err := fc.ReadFrame(); err != io.ErrUnexpectedEOF {
+		t.Errorf("got %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
+
+func TestFrameConnDeadline(t *testing.T) {
+	c1, c2 := net.Pipe()
+	a := NewFrameConn(c1)
+	b := NewFrameConn(c2)
+	defer a.Close()
+	defer b.Close()
+
+	// A frame in progress survives an expired deadline
+	go func() {
+		_, _ = c2.Write([]byte("\x03ab"))
+	}()
+
+	if err := a.SetReadDeadline(time.Now().Add(50 * time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.ReadFrame(); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("got %v, want %v", err, os.ErrDeadlineExceeded)
+	}
+
+	if err := a.SetReadDeadline(time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		_, _ = c2.Write([]byte("\x00"))
+		_ = b.WriteFrame([]byte("c\x00d"))
+	}()
+
+	for _, want := range [][]byte{[]byte("ab"), []byte("c\x00d")} {
+		msg, err := a.ReadFrame()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(msg, want) {
+			t.Errorf("got %q, want %q", msg, want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package cobs
+
+import (
+	"bytes"
+	"net"
+)
+
+// EncodeFramesToBuffers encodes each frame and returns them as net.Buffers,
+// one delimiter terminated buffer per frame. Writing the result to a
+// connection with WriteTo allows a single vectored write for all frames.
+func EncodeFramesToBuffers(frames [][]byte, opts ...Option) (net.Buffers, error) {
+	bufs := make(net.Buffers, 0, len(frames))
+	opts = append(opts[:len(opts):len(opts)], WithDelimiterOnClose(true))
+
+	for _, frame := range frames {
+		enc, err := Encode(frame, opts...)
+		if err != nil {
+			return bufs, err
+		}
+
+		bufs = append(bufs, enc)
+	}
+
+	return bufs, nil
+}
+
+// EncodeFrames encodes each frame and returns the concatenated frames, each
+// terminated by a delimiter. The result can be split again by DecodeFrames
+// using the same options. An empty frame is encoded as a frame with an empty
+// group, no frames result in nil.
+func EncodeFrames(frames [][]byte, opts ...Option) ([]byte, error) {
+	if len(frames) == 0 {
+		return nil, nil
+	}
+
+	opts = append(opts[:len(opts):len(opts)], WithDelimiterOnClose(true), WithRateLimit(0))
+
+	size := 0
+	for _, frame := range frames {
+		size += MaxEncodedLen(len(frame), opts...)
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, size))
+	e := newEncoder(buf, opts...)
+
+	for _, frame := range frames {
+		if _, err := e.Write(frame); err != nil {
+			return buf.Bytes(), err
+		}
+
+		if err := e.Close(); err != nil {
+			return buf.Bytes(), err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// DecodeFrames decodes all delimiter terminated frames in data. If data does
+// not end with a delimiter the frames decoded so far are returned together
+// with a FrameError wrapping ErrIncompleteFrame. A malformed frame stops
+// decoding with a FrameError wrapping ErrUnexpectedEOD, unless WithResync
+// drops it. A lone delimiter results in an empty frame.
+func DecodeFrames(data []byte, opts ...Option) ([][]byte, error) {
+	var frames [][]byte
+
+	// Decoded frames rarely exceed the encoded size
+	buf := make([]byte, 0, len(data))
+	err := splitFrames(data, opts, func(frame []byte) bool {
+		start := len(buf)
+		buf = append(buf, frame...)
+		frames = append(frames, buf[start:len(buf):len(buf)])
+
+		return true
+	})
+
+	return frames, err
+}
+
+// splitFrames decodes the delimiter terminated frames in data, calling fn
+// with each frame until it returns false. The frame is only valid during the
+// call. Errors are reported like DecodeFrames.
+func splitFrames(data []byte, opts []Option, fn func([]byte) bool) error {
+	stopped := false
+	handler := func(frame []byte) error {
+		if !fn(frame) {
+			stopped = true
+
+			return errFrameDone
+		}
+
+		return nil
+	}
+
+	d := newDecoder(nil, append(opts[:len(opts):len(opts)], WithFrameHandler(handler))...)
+	if _, err := d.Write(data); err != nil {
+		if stopped {
+			return nil
+		}
+
+		return err
+	}
+
+	// Only a started frame or delimiter sequence is incomplete
+	if d.offset > 0 || d.delimRest > 0 {
+		return d.frameError(ErrIncompleteFrame, d.offset)
+	}
+
+	return nil
+}
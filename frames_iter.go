@@ -0,0 +1,71 @@
+//go:build go1.23
+
+package cobs
+
+import (
+	"io"
+	"iter"
+)
+
+// Frames returns an iterator over the delimiter terminated frames in data,
+// yielding each decoded frame. Errors are reported like DecodeFrames, as a
+// last yield with a nil frame. The yielded frame reuses an internal buffer
+// and is only valid until the next iteration, it has to be copied to retain
+// it.
+func Frames(data []byte, opts ...Option) iter.Seq2[[]byte, error] {
+	return func(yield func([]byte, error) bool) {
+		err := splitFrames(data, opts, func(frame []byte) bool {
+			return yield(frame, nil)
+		})
+		if err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// ReadFrames returns an iterator over the frames read from r, yielding each
+// decoded frame once it is terminated by a delimiter. A last frame that is
+// not terminated is ended as by Decoder.Close. Errors of decoding and of r
+// are reported as a last yield with a nil frame, io.EOF ends the iteration.
+// Like Frames, the yielded frame is only valid until the next iteration.
+func ReadFrames(r io.Reader, opts ...Option) iter.Seq2[[]byte, error] {
+	return func(yield func([]byte, error) bool) {
+		stopped := false
+		handler := func(frame []byte) error {
+			if !yield(frame, nil) {
+				stopped = true
+
+				return errFrameDone
+			}
+
+			return nil
+		}
+
+		d := newDecoder(nil, append(opts[:len(opts):len(opts)], WithFrameHandler(handler))...)
+		buf := make([]byte, 4096)
+
+		for {
+			n, err := r.Read(buf)
+
+			_, derr := d.Write(buf[:n])
+			if stopped {
+				return
+			}
+			if derr != nil {
+				yield(nil, derr)
+				return
+			}
+
+			switch {
+			case err == io.EOF:
+				if cerr := d.Close(); cerr != nil && !stopped {
+					yield(nil, cerr)
+				}
+				return
+			case err != nil:
+				yield(nil, err)
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,171 @@
+//go:build go1.23
+
+package cobs
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestFrames(t *testing.T) {
+	testCases := []struct {
+		name   string
+		data   []byte
+		opts   []Option
+		frames []string
+		err    error
+	}{
+		{
+			name:   "Complete",
+			data:   []byte("\x02a\x00\x01\x00\x03bc\x00"),
+			frames: []string{"a", "", "bc"},
+		},
+		{
+			name:   "Incomplete tail",
+			data:   []byte("\x02a\x00\x04bc"),
+			frames: []string{"a"},
+			err:    ErrIncompleteFrame,
+		},
+		{
+			name:   "Malformed frame",
+			data:   []byte("\x02a\x00\x05ab\x00\x02c\x00"),
+			frames: []string{"a"},
+			err:    ErrUnexpectedEOD,
+		},
+		{
+			name:   "Trailing skipped delimiter",
+			data:   []byte("\x02a\x00\x00"),
+			opts:   []Option{WithSkipEmptyFrames(true)},
+			frames: []string{"a"},
+		},
+		{
+			name:   "Resync drops partial data",
+			data:   []byte("\x02a\x00\x03x\x00\x02b\x00"),
+			opts:   []Option{WithResync(true)},
+			frames: []string{"a", "b"},
+		},
+		{
+			name:   "Keep-alives are not frames",
+			data:   []byte("\x00\x02a\x00\x00"),
+			opts:   []Option{WithKeepAlive(func() error { return nil })},
+			frames: []string{"a"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var frames []string
+			var err error
+
+			for frame, ferr := range Frames(tc.data, tc.opts...) {
+				if ferr != nil {
+					err = ferr
+					continue
+				}
+				frames = append(frames, string(frame))
+			}
+
+			if !errors.Is(err, tc.err) {
+				t.Errorf("got %v, want %v", err, tc.err)
+			}
+			if len(frames) != len(tc.frames) {
+				t.Fatalf("got %q, want %q", frames, tc.frames)
+			}
+			for i := range frames {
+				if frames[i] != tc.frames[i] {
+					t.Errorf("frame %d: got %q, want %q", i, frames[i], tc.frames[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFramesBreak(t *testing.T) {
+	n := 0
+	for range Frames([]byte("\x02a\x00\x02b\x00\x02c\x00")) {
+		n++
+		if n == 2 {
+			break
+		}
+	}
+
+	if n != 2 {
+		t.Errorf("got %d frames, want 2", n)
+	}
+}
+
+func TestReadFrames(t *testing.T) {
+	testCases := []struct {
+		name   string
+		data   string
+		frames []string
+		err    error
+	}{
+		{
+			name:   "Complete",
+			data:   "\x02a\x00\x01\x00\x03bc\x00",
+			frames: []string{"a", "", "bc"},
+		},
+		{
+			name:   "Unterminated tail",
+			data:   "\x02a\x00\x03bc",
+			frames: []string{"a", "bc"},
+		},
+		{
+			name:   "Incomplete tail",
+			data:   "\x02a\x00\x04bc",
+			frames: []string{"a"},
+			err:    ErrIncompleteFrame,
+		},
+		{
+			name:   "Malformed frame",
+			data:   "\x02a\x00\x05ab\x00\x02c\x00",
+			frames: []string{"a"},
+			err:    ErrUnexpectedEOD,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var frames []string
+			var err error
+
+			r := iotest.OneByteReader(strings.NewReader(tc.data))
+			for frame, ferr := range ReadFrames(r) {
+				if ferr != nil {
+					err = ferr
+					continue
+				}
+				frames = append(frames, string(frame))
+			}
+
+			if !errors.Is(err, tc.err) {
+				t.Errorf("got %v, want %v", err, tc.err)
+			}
+			if len(frames) != len(tc.frames) {
+				t.Fatalf("got %q, want %q", frames, tc.frames)
+			}
+			for i := range frames {
+				if frames[i] != tc.frames[i] {
+					t.Errorf("frame %d: got %q, want %q", i, frames[i], tc.frames[i])
+				}
+			}
+		})
+	}
+}
+
+func TestReadFramesBreak(t *testing.T) {
+	n := 0
+	for range ReadFrames(strings.NewReader("\x02a\x00\x02b\x00\x02c\x00")) {
+		n++
+		if n == 2 {
+			break
+		}
+	}
+
+	if n != 2 {
+		t.Errorf("got %d frames, want 2", n)
+	}
+}
@@ -0,0 +1,201 @@
+package cobs
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// decodeStream decodes all delimiter terminated frames in data.
+func decodeStream(t *testing.T, data []byte, opts ...Option) [][]byte {
+	t.Helper()
+
+	var frames [][]byte
+	var buf bytes.Buffer
+	d := mustDecoder(t, &buf, opts...)
+
+	for len(data) > 0 {
+		n, err := d.Write(data)
+		if err != EOD {
+			t.Fatalf("decode stream error: %v", err)
+		}
+
+		frames = append(frames, append([]byte{}, buf.Bytes()...))
+		buf.Reset()
+		data = data[n+1:]
+	}
+
+	return frames
+}
+
+func TestEncodeFramesToBuffers(t *testing.T) {
+	var frames [][]byte
+	for _, tc := range testCases {
+		frames = append(frames, tc.dec)
+	}
+
+	bufs, err := EncodeFramesToBuffers(frames)
+	if err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+	if len(bufs) != len(frames) {
+		t.Fatalf("got %d buffers, want %d", len(bufs), len(frames))
+	}
+
+	var stream bytes.Buffer
+	if _, err := bufs.WriteTo(&stream); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+
+	got := decodeStream(t, stream.Bytes())
+	if len(got) != len(frames) {
+		t.Fatalf("got %d frames, want %d", len(got), len(frames))
+	}
+	for i := range frames {
+		if !bytes.Equal(got[i], frames[i]) {
+			t.Errorf("frame %d got %v, want %v", i, got[i], frames[i])
+		}
+	}
+}
+
+func TestDecodeFrames(t *testing.T) {
+	for _, reduced := range []bool{false, true} {
+		var frames [][]byte
+		var data []byte
+		for _, tc := range testCases {
+			enc, err := Encode(tc.dec, WithReduced(reduced), WithDelimiterOnClose(true))
+			if err != nil {
+				t.Fatalf("encode error: %v", err)
+			}
+
+			frames = append(frames, tc.dec)
+			data = append(data, enc...)
+		}
+
+		got, err := DecodeFrames(data, WithReduced(reduced))
+		if err != nil {
+			t.Fatalf("decode frames error: %v", err)
+		}
+		if len(got) != len(frames) {
+			t.Fatalf("got %d frames, want %d", len(got), len(frames))
+		}
+		for i := range frames {
+			if !bytes.Equal(got[i], frames[i]) {
+				t.Errorf("frame %d got %v, want %v", i, got[i], frames[i])
+			}
+		}
+	}
+}
+
+func TestDecodeFramesErrors(t *testing.T) {
+	got, err := DecodeFrames([]byte("\x02a\x00\x00\x03bc"))
+	if !errors.Is(err, ErrIncompleteFrame) {
+		t.Errorf("got %v, want %v", err, ErrIncompleteFrame)
+	}
+	if len(got) != 2 || !bytes.Equal(got[0], []byte("a")) || len(got[1]) != 0 {
+		t.Errorf("got frames %v", got)
+	}
+
+	got, err = DecodeFrames([]byte("\x02a\x00\x03b\x00\x02c\x00"))
+	if !errors.Is(err, ErrUnexpectedEOD) {
+		t.Errorf("got %v, want %v", err, ErrUnexpectedEOD)
+	}
+	if len(got) != 1 {
+		t.Errorf("got %d frames, want 1", len(got))
+	}
+}
+
+func TestDecodeFramesOptions(t *testing.T) {
+	testCases := []struct {
+		name   string
+		data   []byte
+		opts   []Option
+		frames []string
+	}{
+		{
+			name:   "Trailing skipped delimiter",
+			data:   []byte("\x02a\x00\x00"),
+			opts:   []Option{WithSkipEmptyFrames(true)},
+			frames: []string{"a"},
+		},
+		{
+			name:   "Resync drops partial data",
+			data:   []byte("\x02a\x00\x03x\x00\x02b\x00"),
+			opts:   []Option{WithResync(true)},
+			frames: []string{"a", "b"},
+		},
+		{
+			name:   "Keep-alives are not frames",
+			data:   []byte("\x00\x02a\x00\x00"),
+			opts:   []Option{WithKeepAlive(func() error { return nil })},
+			frames: []string{"a"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := DecodeFrames(tc.data, tc.opts...)
+			if err != nil {
+				t.Fatalf("decode frames error: %v", err)
+			}
+			if len(got) != len(tc.frames) {
+				t.Fatalf("got %q, want %q", got, tc.frames)
+			}
+			for i := range got {
+				if string(got[i]) != tc.frames[i] {
+					t.Errorf("frame %d: got %q, want %q", i, got[i], tc.frames[i])
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeFrames(t *testing.T) {
+	enc, err := EncodeFrames(nil)
+	if enc != nil || err != nil {
+		t.Errorf("got %v, %v for no frames", enc, err)
+	}
+
+	enc, err = EncodeFrames([][]byte{[]byte("ab"), {}, {0x00}}, WithSentinel('x'))
+	if err != nil {
+		t.Fatalf("encode frames error: %v", err)
+	}
+
+	want := []byte{0x03 ^ 'x', 'a' ^ 'x', 'b' ^ 'x', 'x', 0x01 ^ 'x', 'x', 0x01 ^ 'x', 0x01 ^ 'x', 'x'}
+	if !bytes.Equal(enc, want) {
+		t.Errorf("got %v, want %v", enc, want)
+	}
+}
+
+func FuzzEncodeFrames(f *testing.F) {
+	f.Add([]byte("ab|cd"), byte(0x00), false)
+	f.Add([]byte("|\x00||"), byte('|'), true)
+	for _, tc := range testCases {
+		f.Add(tc.dec, byte(0x00), false)
+		f.Add(tc.dec, byte(0xff), true)
+	}
+	f.Fuzz(func(t *testing.T, a []byte, sentinel byte, reduced bool) {
+		frames := bytes.Split(a, []byte{'|'})
+
+		enc, err := EncodeFrames(frames, WithSentinel(sentinel), WithReduced(reduced))
+		if err != nil {
+			t.Fatalf("fuzz encode frames error: %v", err)
+		}
+		if n := bytes.Count(enc, []byte{sentinel}); n != len(frames) {
+			t.Errorf("fuzz encode frames has %d sentinels, want %d", n, len(frames))
+		}
+
+		dec, err := DecodeFrames(enc, WithSentinel(sentinel), WithReduced(reduced))
+		if err != nil {
+			t.Fatalf("fuzz decode frames error: %v", err)
+		}
+		if len(dec) != len(frames) {
+			t.Fatalf("fuzz decode frames got %d frames, want %d", len(dec), len(frames))
+		}
+		for i := range frames {
+			if !bytes.Equal(dec[i], frames[i]) {
+				t.Errorf("fuzz decode frame %d got %v, want %v", i, dec[i], frames[i])
+			}
+		}
+	})
+}
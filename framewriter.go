@@ -0,0 +1,81 @@
+package cobs
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrFrameClosed means that a frame returned by NextFrame was written to
+// after it was closed, or after the next frame was started.
+var ErrFrameClosed = errors.New("frame closed")
+
+// A FrameWriter writes a sequence of delimiter terminated frames to an
+// io.Writer, one frame per io.WriteCloser returned by NextFrame, similar to
+// the parts of a multipart.Writer.
+type FrameWriter struct {
+	e   *Encoder
+	cur *frameWriter
+}
+
+// NewFrameWriter returns a FrameWriter that writes encoded frames to w. The
+// options are passed to the Encoder, a delimiter is always written after
+// each frame and w is not closed.
+func NewFrameWriter(w io.Writer, opts ...Option) *FrameWriter {
+	return &FrameWriter{
+		e: newEncoder(w, append(opts[:len(opts):len(opts)], WithDelimiterOnClose(true), WithCloseUnderlying(false))...),
+	}
+}
+
+// NextFrame starts a new frame and returns a writer for its payload. Closing
+// the writer ends the frame and writes the delimiter. A frame that is still
+// open is closed first, an error doing so is returned by the writes of the
+// new frame.
+func (fw *FrameWriter) NextFrame() io.WriteCloser {
+	f := &frameWriter{fw: fw}
+
+	if fw.cur != nil {
+		f.err = fw.cur.Close()
+	}
+	fw.cur = f
+
+	return f
+}
+
+// Close closes the frame that is still open, if any.
+func (fw *FrameWriter) Close() error {
+	if fw.cur == nil {
+		return nil
+	}
+
+	return fw.cur.Close()
+}
+
+// A frameWriter writes the payload of a single frame.
+type frameWriter struct {
+	fw     *FrameWriter
+	closed bool
+	err    error
+}
+
+func (f *frameWriter) Write(p []byte) (int, error) {
+	if f.closed {
+		return 0, ErrFrameClosed
+	}
+
+	if f.err != nil {
+		return 0, f.err
+	}
+
+	return f.fw.e.Write(p)
+}
+
+// Close ends the frame. Closing it again has no effect.
+func (f *frameWriter) Close() error {
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+	f.fw.cur = nil
+
+	return f.fw.e.Close()
+}
@@ -0,0 +1,45 @@
+package cobs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestFrameWriter(t *testing.T) {
+	var buf bytes.Buffer
+	fw := NewFrameWriter(&buf, WithSentinel(0x42))
+
+	f := fw.NextFrame()
+	if _, err := io.WriteString(f, "Hello"); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("close error: %v", err)
+	}
+	if _, err := f.Write([]byte("late")); !errors.Is(err, ErrFrameClosed) {
+		t.Errorf("got %v, want %v", err, ErrFrameClosed)
+	}
+
+	// Starting a frame closes the open one
+	f = fw.NextFrame()
+	if _, err := f.Write([]byte("a\x00b")); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	g := fw.NextFrame()
+	if _, err := f.Write([]byte("c")); !errors.Is(err, ErrFrameClosed) {
+		t.Errorf("got %v, want %v", err, ErrFrameClosed)
+	}
+	if _, err := g.Write([]byte("world")); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	if err := fw.Close(); err != nil {
+		t.Fatalf("close error: %v", err)
+	}
+
+	want, _ := EncodeFrames([][]byte{[]byte("Hello"), []byte("a\x00b"), []byte("world")}, WithSentinel(0x42))
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("got %v, want %v", buf.Bytes(), want)
+	}
+}
@@ -0,0 +1,111 @@
+package cobs
+
+import (
+	"sort"
+	"sync"
+)
+
+// A Histogram collects the distribution of the payload sizes of frames and
+// the overhead of their encoding, the encoded bytes including delimiters
+// minus the payload bytes. With WithZPE and WithZRE runs of zeros can encode
+// to fewer bytes than the payload, so the overhead can be negative. Frames
+// dropped by a Decoder because of a frame
+// error are counted as well. A Histogram can be shared by Encoders and
+// Decoders with WithHistogram and queried while they are in use.
+type Histogram struct {
+	mu       sync.Mutex
+	bounds   []int
+	sizes    []uint64
+	overhead []uint64
+	frames   uint64
+	dropped  uint64
+	sizeSum  uint64
+	overSum  int64
+}
+
+// A HistogramSnapshot holds the counts of a Histogram at one point in time.
+// Bucket i counts the values up to Bounds[i] that exceed the previous bound,
+// the last bucket counts the values exceeding all bounds. The first bucket
+// counts all values up to Bounds[0], which includes negative overhead unless
+// there are negative bounds.
+type HistogramSnapshot struct {
+	Bounds      []int
+	Sizes       []uint64 // frames per bucket of payload size
+	Overhead    []uint64 // frames per bucket of encoding overhead
+	Frames      uint64   // complete frames
+	Dropped     uint64   // frames dropped by a frame error
+	SizeSum     uint64   // payload bytes of all frames
+	OverheadSum int64    // overhead bytes of all frames, negative if saved
+}
+
+// NewHistogram returns a Histogram with buckets up to the given bounds. If no
+// bounds are given, they are 0 and the powers of two up to 64 KiB. Bounds
+// may be negative to tell apart the negative overhead of WithZPE and WithZRE.
+func NewHistogram(bounds ...int) *Histogram {
+	if len(bounds) == 0 {
+		bounds = []int{0}
+		for b := 1; b <= 64<<10; b <<= 1 {
+			bounds = append(bounds, b)
+		}
+	}
+
+	bounds = append([]int(nil), bounds...)
+	sort.Ints(bounds)
+
+	return &Histogram{
+		bounds:   bounds,
+		sizes:    make([]uint64, len(bounds)+1),
+		overhead: make([]uint64, len(bounds)+1),
+	}
+}
+
+// record counts a complete frame of size payload bytes and the encoding
+// overhead.
+func (h *Histogram) record(size, overhead int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.sizes[sort.SearchInts(h.bounds, size)]++
+	h.overhead[sort.SearchInts(h.bounds, overhead)]++
+	h.frames++
+	h.sizeSum += uint64(size)
+	h.overSum += int64(overhead)
+}
+
+// drop counts a dropped frame.
+func (h *Histogram) drop() {
+	h.mu.Lock()
+	h.dropped++
+	h.mu.Unlock()
+}
+
+// Snapshot returns a copy of the current counts.
+func (h *Histogram) Snapshot() HistogramSnapshot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return HistogramSnapshot{
+		Bounds:      append([]int(nil), h.bounds...),
+		Sizes:       append([]uint64(nil), h.sizes...),
+		Overhead:    append([]uint64(nil), h.overhead...),
+		Frames:      h.frames,
+		Dropped:     h.dropped,
+		SizeSum:     h.sizeSum,
+		OverheadSum: h.overSum,
+	}
+}
+
+// Reset clears all counts.
+func (h *Histogram) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i := range h.sizes {
+		h.sizes[i] = 0
+		h.overhead[i] = 0
+	}
+	h.frames = 0
+	h.dropped = 0
+	h.sizeSum = 0
+	h.overSum = 0
+}
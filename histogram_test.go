@@ -0,0 +1,105 @@
+package cobs
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestHistogram(t *testing.T) {
+	frames := [][]byte{{}, []byte("a"), bytes.Repeat([]byte("x"), 300)}
+
+	check := func(t *testing.T, s HistogramSnapshot) {
+		t.Helper()
+
+		// Payload sizes up to 0, 1 and 512
+		for i, want := range map[int]uint64{0: 1, 1: 1, 10: 1} {
+			if s.Sizes[i] != want {
+				t.Errorf("size bucket %d: got %d, want %d", i, s.Sizes[i], want)
+			}
+		}
+		// Overhead of 2, 2 and 3 bytes
+		for i, want := range map[int]uint64{2: 2, 3: 1} {
+			if s.Overhead[i] != want {
+				t.Errorf("overhead bucket %d: got %d, want %d", i, s.Overhead[i], want)
+			}
+		}
+		if s.Frames != 3 || s.SizeSum != 301 || s.OverheadSum != 7 {
+			t.Errorf("got %d frames, %d bytes, %d overhead", s.Frames, s.SizeSum, s.OverheadSum)
+		}
+	}
+
+	he := NewHistogram()
+	var enc bytes.Buffer
+	e := mustEncoder(t, &enc, WithHistogram(he))
+	for _, f := range frames {
+		if _, err := e.WriteFrame(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	check(t, he.Snapshot())
+
+	// A dropped frame is counted as well
+	hd := NewHistogram()
+	d := mustDecoder(t, nil, WithHistogram(hd), WithResync(true),
+		WithFrameHandler(func([]byte) error { return nil }))
+	if _, err := d.Write(append([]byte("\x05ab\x00"), enc.Bytes()...)); err != nil {
+		t.Fatal(err)
+	}
+
+	s := hd.Snapshot()
+	check(t, s)
+	if s.Dropped != 1 {
+		t.Errorf("got %d dropped, want 1", s.Dropped)
+	}
+
+	hd.Reset()
+	if s := hd.Snapshot(); s.Frames != 0 || s.Dropped != 0 || s.Sizes[0] != 0 {
+		t.Errorf("got %+v after reset", s)
+	}
+}
+
+func TestHistogramBounds(t *testing.T) {
+	h := NewHistogram(100, 10)
+	h.record(10, 1)
+	h.record(50, 1)
+	h.record(1000, 1)
+
+	s := h.Snapshot()
+	if s.Bounds[0] != 10 || s.Bounds[1] != 100 {
+		t.Errorf("got bounds %v, want [10 100]", s.Bounds)
+	}
+	for i, want := range []uint64{1, 1, 1} {
+		if s.Sizes[i] != want {
+			t.Errorf("bucket %d: got %d, want %d", i, s.Sizes[i], want)
+		}
+	}
+}
+
+func TestHistogramNegativeOverhead(t *testing.T) {
+	for name, opt := range map[string]Option{"zre": WithZRE(true), "zpe": WithZPE(true)} {
+		t.Run(name, func(t *testing.T) {
+			h := NewHistogram(-10, 0, 10)
+			var enc bytes.Buffer
+			e := mustEncoder(t, &enc, opt, WithHistogram(h))
+
+			// Runs of zeros encode to fewer bytes than the payload
+			payload := make([]byte, 40)
+			if _, err := e.WriteFrame(payload); err != nil {
+				t.Fatal(err)
+			}
+
+			want := int64(enc.Len() - len(payload))
+			if want >= -10 {
+				t.Fatalf("overhead %d is not below -10", want)
+			}
+
+			s := h.Snapshot()
+			if s.OverheadSum != want {
+				t.Errorf("got overhead sum %d, want %d", s.OverheadSum, want)
+			}
+			if s.Overhead[0] != 1 {
+				t.Errorf("got overhead buckets %v, want the frame below -10", s.Overhead)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package cobs
+
+import "fmt"
+
+// DecodeInPlace decodes the frame in buf into buf itself and returns the
+// length of the decoded data, which is never longer than the frame. Like
+// Validate, buf must hold a single complete frame, optionally terminated by a
+// delimiter, otherwise a FrameError is returned and buf is left unchanged.
+// The options that apply are WithSentinel, WithInsertedByte, WithReduced and
+// WithMaxGroupSize, and the first byte of WithDelimiterSequence as sentinel.
+// WithZPE, WithZRE, WithSecondForbidden, WithSentinelMode(SentinelNative),
+// WithChecksum, WithLengthPrefix and WithSequence need more state and return
+// ErrIncompatibleOptions. Other options are ignored rather than rejected.
+// DecodeInPlace does not allocate, unless the frame is invalid.
+func DecodeInPlace(buf []byte, opts ...Option) (int, error) {
+	c := newConfig(opts)
+	if c.ZPE || c.ZRE || c.SecondForbidden || c.SentinelMode != SentinelXOR || c.Checksum != ChecksumNone || c.LengthPrefix || c.Sequence {
+		return 0, fmt.Errorf("%w: DecodeInPlace", ErrIncompatibleOptions)
+	}
+
+	if err := Validate(buf, opts...); err != nil {
+		return 0, err
+	}
+
+	n := len(buf)
+	if buf[n-1] == c.Sentinel {
+		n--
+	}
+
+	full := c.fullCode()
+	w := 0
+	for r := 0; r < n; {
+		code := buf[r] ^ c.Sentinel
+		r++
+
+		end := r + int(code) - 1
+		if end > n {
+			// The code of a reduced last group is its last data byte
+			for ; r < n; r++ {
+				buf[w] = buf[r] ^ c.Sentinel
+				w++
+			}
+			buf[w] = code
+			w++
+
+			break
+		}
+
+		for ; r < end; r++ {
+			buf[w] = buf[r] ^ c.Sentinel
+			w++
+		}
+
+		if code != full && r < n {
+			buf[w] = c.InsertedByte
+			w++
+		}
+	}
+
+	return w, nil
+}
@@ -0,0 +1,80 @@
+package cobs
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestDecodeInPlace(t *testing.T) {
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := append([]byte(nil), tc.enc...)
+
+			n, err := DecodeInPlace(buf)
+			if err != nil {
+				t.Fatalf("decode error: %v", err)
+			}
+			if !bytes.Equal(buf[:n], tc.dec) {
+				t.Errorf("got %v, want %v", buf[:n], tc.dec)
+			}
+		})
+	}
+}
+
+func TestDecodeInPlaceOptions(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789\x00\x42"), 50)
+
+	for _, opts := range [][]Option{
+		{WithDelimiterOnClose(true)},
+		{WithSentinel(0x42)},
+		{WithReduced(true)},
+		{WithReduced(true), WithSentinel(0x42), WithDelimiterOnClose(true)},
+	} {
+		for _, data := range [][]byte{data, []byte("a\x00b"), []byte("\x00\x00")} {
+			buf, err := Encode(data, opts...)
+			if err != nil {
+				t.Fatalf("encode error: %v", err)
+			}
+
+			n, err := DecodeInPlace(buf, opts...)
+			if err != nil {
+				t.Fatalf("decode error: %v", err)
+			}
+			if !bytes.Equal(buf[:n], data) {
+				t.Errorf("got %v, want %v", buf[:n], data)
+			}
+		}
+	}
+}
+
+func TestDecodeInPlaceErrors(t *testing.T) {
+	buf := []byte("\x03a\x00b")
+
+	_, err := DecodeInPlace(buf)
+
+	var fe *FrameError
+	if !errors.As(err, &fe) || !errors.Is(err, ErrUnexpectedEOD) || fe.Offset != 2 {
+		t.Errorf("got %v, want %v at offset 2", err, ErrUnexpectedEOD)
+	}
+	if !bytes.Equal(buf, []byte("\x03a\x00b")) {
+		t.Errorf("buffer changed to %v", buf)
+	}
+
+	if _, err := DecodeInPlace([]byte("\x01"), WithZPE(true)); !errors.Is(err, ErrIncompatibleOptions) {
+		t.Errorf("got %v, want %v", err, ErrIncompatibleOptions)
+	}
+}
+
+func TestDecodeInPlaceAllocs(t *testing.T) {
+	enc, _ := Encode(bytes.Repeat([]byte("0123456789\x00"), 100))
+	buf := make([]byte, len(enc))
+
+	allocs := testing.AllocsPerRun(100, func() {
+		copy(buf, enc)
+		_, _ = DecodeInPlace(buf)
+	})
+	if allocs > 0 {
+		t.Errorf("got %v allocations, want 0", allocs)
+	}
+}
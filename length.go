@@ -0,0 +1,66 @@
+package cobs
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// ErrInvalidLength means that the length prefix of a decoded frame is
+// malformed or does not match the length of its payload.
+var ErrInvalidLength = errors.New("invalid length")
+
+// closePayload writes the length prefix and payload buffered for it, followed
+// by the padding up to the minimum payload length.
+func (e *Encoder) closePayload() error {
+	n := e.size
+	e.size = 0
+
+	if e.prefix {
+		var b [binary.MaxVarintLen64]byte
+		for _, c := range b[:binary.PutUvarint(b[:], uint64(len(e.payload)))] {
+			if err := e.put(c); err != nil {
+				return err
+			}
+		}
+
+		for _, c := range e.payload {
+			if err := e.put(c); err != nil {
+				return err
+			}
+		}
+
+		n = len(e.payload)
+		e.payload = e.payload[:0]
+	}
+
+	for ; n < e.minLen; n++ {
+		if err := e.put(e.pad); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// unprefix parses the length prefix of a frame and reports whether c is part
+// of the payload that follows it, bytes beyond its length are padding.
+func (d *Decoder) unprefix(c byte) (bool, error) {
+	if !d.lenDone {
+		if d.lenShift >= 64 {
+			return false, d.frameError(ErrInvalidLength, d.offset-1)
+		}
+
+		d.length |= uint64(c&0x7f) << d.lenShift
+		d.lenShift += 7
+		d.lenDone = c < 0x80
+
+		return false, nil
+	}
+
+	if d.length == 0 {
+		return false, nil
+	}
+	d.length--
+
+	return true, nil
+}
@@ -0,0 +1,97 @@
+package cobs
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestMinPayload(t *testing.T) {
+	testCases := []struct {
+		name string
+		dec  []byte
+		enc  []byte
+	}{
+		{
+			name: "Empty",
+			dec:  []byte{},
+			enc:  []byte("\x01\x05\xee\xee\xee\xee"),
+		},
+		{
+			name: "Short",
+			dec:  []byte("ab"),
+			enc:  []byte("\x06\x02ab\xee\xee"),
+		},
+		{
+			name: "Long enough",
+			dec:  []byte("abcdef"),
+			enc:  []byte("\x08\x06abcdef"),
+		},
+		{
+			name: "Zeros",
+			dec:  []byte{0x00, 0x00},
+			enc:  []byte("\x02\x02\x01\x03\xee\xee"),
+		},
+	}
+
+	opts := []Option{WithMinPayload(4, 0xee), WithLengthPrefix(true)}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			enc, err := Encode(tc.dec, opts...)
+			if err != nil {
+				t.Fatalf("encode error: %v", err)
+			}
+			if !bytes.Equal(enc, tc.enc) {
+				t.Errorf("got %v, want %v", enc, tc.enc)
+			}
+
+			dec, err := Decode(enc, opts...)
+			if err != nil {
+				t.Fatalf("decode error: %v", err)
+			}
+			if !bytes.Equal(dec, tc.dec) {
+				t.Errorf("got %v, want %v", dec, tc.dec)
+			}
+		})
+	}
+}
+
+func TestMinPayloadWithoutPrefix(t *testing.T) {
+	enc, err := Encode([]byte("ab"), WithMinPayload(4, 0xee))
+	if err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+
+	// The padding is kept without length
+	dec, err := Decode(enc)
+	if err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if string(dec) != "ab\xee\xee" {
+		t.Errorf("got %q", dec)
+	}
+}
+
+func TestLengthPrefix(t *testing.T) {
+	opts := []Option{WithLengthPrefix(true), WithChecksum(CRC16CCITT)}
+	payload := bytes.Repeat([]byte("0123456789\x00"), 100)
+
+	enc, err := Encode(payload, opts...)
+	if err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+	dec, err := Decode(enc, opts...)
+	if err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if !bytes.Equal(dec, payload) {
+		t.Errorf("got %v, want %v", dec, payload)
+	}
+
+	// The prefix claims more than the payload
+	_, err = Decode([]byte("\x04\x05ab"), WithLengthPrefix(true))
+	if !errors.Is(err, ErrInvalidLength) {
+		t.Errorf("got %v, want %v", err, ErrInvalidLength)
+	}
+}
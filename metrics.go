@@ -0,0 +1,51 @@
+package cobs
+
+// Metrics receives the events of an Encoder or Decoder, so they can be counted
+// by a metrics system like Prometheus or expvar without this package
+// depending on it. The methods are called from the methods of the Encoder or
+// Decoder, they should return quickly. A Metrics shared by several Encoders
+// and Decoders has to be safe for concurrent use.
+type Metrics interface {
+	// FrameEncoded is called for every frame an Encoder ended, with the
+	// number of payload bytes and of encoded bytes, including delimiters.
+	FrameEncoded(payload, encoded int)
+	// FrameDecoded is called for every complete frame of a Decoder, with the
+	// number of decoded and of encoded bytes, including the delimiter.
+	FrameDecoded(payload, encoded int)
+	// Error is called for every frame error of a Decoder and for every
+	// failed write.
+	Error(err error)
+	// Resync is called when a Decoder dropped a frame with WithResync and
+	// continued with the next one.
+	Resync()
+}
+
+// recordFrame reports the frame that was just ended to the histogram and
+// metrics.
+func (e *Encoder) recordFrame() {
+	size := int(e.stats.BytesIn - e.frameIn)
+	encoded := int(e.produced - e.frameOut)
+	e.frameIn = e.stats.BytesIn
+	e.frameOut = e.produced
+
+	if e.hist != nil {
+		e.hist.record(size, encoded-size)
+	}
+
+	if e.metrics != nil {
+		e.metrics.FrameEncoded(size, encoded)
+	}
+}
+
+// recordFrame reports the complete frame to the histogram and metrics.
+func (d *Decoder) recordFrame() {
+	encoded := int(int64(d.stats.BytesIn) - d.start)
+
+	if d.hist != nil {
+		d.hist.record(d.size, encoded-d.size)
+	}
+
+	if d.metrics != nil {
+		d.metrics.FrameDecoded(d.size, encoded)
+	}
+}
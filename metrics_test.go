@@ -0,0 +1,64 @@
+package cobs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+// recordMetrics records the events it receives.
+type recordMetrics struct {
+	events []string
+}
+
+func (m *recordMetrics) FrameEncoded(payload, encoded int) {
+	m.events = append(m.events, fmt.Sprintf("encoded %d %d", payload, encoded))
+}
+
+func (m *recordMetrics) FrameDecoded(payload, encoded int) {
+	m.events = append(m.events, fmt.Sprintf("decoded %d %d", payload, encoded))
+}
+
+func (m *recordMetrics) Error(err error) {
+	m.events = append(m.events, "error")
+}
+
+func (m *recordMetrics) Resync() {
+	m.events = append(m.events, "resync")
+}
+
+func TestMetrics(t *testing.T) {
+	var m recordMetrics
+
+	var enc bytes.Buffer
+	e := mustEncoder(t, &enc, WithMetrics(&m), WithDelimiterOnClose(true))
+	if _, err := e.Write([]byte("ab\x00c")); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	d := mustDecoder(t, nil, WithMetrics(&m), WithResync(true),
+		WithFrameHandler(func([]byte) error { return nil }))
+	if _, err := d.Write(append([]byte("\x05ab\x00"), enc.Bytes()...)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"encoded 4 6", "error", "resync", "decoded 4 6"}
+	if fmt.Sprint(m.events) != fmt.Sprint(want) {
+		t.Errorf("got %q, want %q", m.events, want)
+	}
+
+	// A failed write is an error
+	m.events = nil
+	e = mustEncoder(t, &shortWriter{max: 0}, WithMetrics(&m))
+	if _, err := e.Write([]byte("abc\x00")); !errors.Is(err, io.ErrShortWrite) {
+		t.Fatalf("got %v, want %v", err, io.ErrShortWrite)
+	}
+	if fmt.Sprint(m.events) != "[error]" {
+		t.Errorf("got %q, want %q", m.events, []string{"error"})
+	}
+}
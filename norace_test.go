@@ -0,0 +1,5 @@
+//go:build !race
+
+package cobs
+
+const raceEnabled = false
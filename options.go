@@ -0,0 +1,606 @@
+package cobs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrIncompatibleOptions means that options were combined that can not
+	// be used together. NewEncoder and NewDecoder return it, as do the helper
+	// functions. Types built on them, like Reader, return it from every call.
+	ErrIncompatibleOptions = errors.New("incompatible options")
+
+	// ErrInvalidOption means that an option was given an invalid value. It
+	// is returned like ErrIncompatibleOptions.
+	ErrInvalidOption = errors.New("invalid option")
+)
+
+// An Option configures an Encoder, a Decoder or one of the helper functions
+// by setting fields of a Config. An error returned by an Option is returned
+// by NewEncoder and NewDecoder, the remaining options are still applied.
+// Options that do not apply are ignored.
+type Option func(*Config) error
+
+// configPool avoids allocating a config that options are applied to.
+var configPool = sync.Pool{
+	New: func() any {
+		return new(Config)
+	},
+}
+
+func newConfig(opts []Option) Config {
+	p := configPool.Get().(*Config)
+	*p = Config{
+		clock: realClock{},
+	}
+
+	for _, opt := range opts {
+		if err := opt(p); err != nil && p.err == nil {
+			p.err = err
+		}
+	}
+
+	c := *p
+	*p = Config{}
+	configPool.Put(p)
+
+	return c
+}
+
+// check returns the first error of an option, or an error if c combines
+// options that can not be used together.
+func (c *Config) check() error {
+	if c.err != nil {
+		return c.err
+	}
+
+	if len(c.delimSeq) > 0 && c.delimSeq[0] != c.Sentinel {
+		return fmt.Errorf("%w: WithDelimiterSequence and WithSentinel", ErrIncompatibleOptions)
+	}
+
+	if c.SentinelMode == SentinelNative && (c.SecondForbidden || c.InsertedByte != 0) {
+		return fmt.Errorf("%w: SentinelNative", ErrIncompatibleOptions)
+	}
+
+	if c.MaxGroupSize > 0 && (c.Reduced || c.ZPE || c.ZRE || c.SecondForbidden) {
+		return fmt.Errorf("%w: WithMaxGroupSize", ErrIncompatibleOptions)
+	}
+
+	if c.ZPE && c.Reduced {
+		return fmt.Errorf("%w: WithZPE and WithReduced", ErrIncompatibleOptions)
+	}
+
+	if c.ZRE && (c.Reduced || c.ZPE) {
+		return fmt.Errorf("%w: WithZRE", ErrIncompatibleOptions)
+	}
+
+	if c.SecondForbidden && (c.Reduced || c.ZPE || c.ZRE || c.Forbidden == c.Sentinel) {
+		return fmt.Errorf("%w: WithSecondForbidden", ErrIncompatibleOptions)
+	}
+
+	return nil
+}
+
+// CheckOptions returns the error NewEncoder and NewDecoder would return for
+// opts, wrapping ErrInvalidOption or ErrIncompatibleOptions. This allows
+// rejecting a configuration up front, before it is passed to a type that
+// reports it on every call.
+func CheckOptions(opts ...Option) error {
+	c := newConfig(opts)
+
+	return c.check()
+}
+
+// WithReduced enables the COBS/R variant, which saves the overhead byte of
+// most frames by replacing the code of the last group with its last data byte
+// if that byte is larger. Encoder and Decoder must agree on this setting.
+// As a consequence COBS/R frames that end in the middle of a group are valid,
+// and such truncation can no longer be detected while decoding.
+func WithReduced(enable bool) Option {
+	return func(c *Config) error {
+		c.Reduced = enable
+
+		return nil
+	}
+}
+
+// WithZPE enables the COBS/ZPE variant, zero pair elimination, which encodes
+// a pair of zeros in the code of a group. This reduces the size of payloads
+// with many adjacent zeros. Groups are limited to 223 bytes instead of 254.
+// Encoder and Decoder must agree on this setting. It can not be combined with
+// WithReduced, NewEncoder and NewDecoder then return ErrIncompatibleOptions.
+func WithZPE(enable bool) Option {
+	return func(c *Config) error {
+		c.ZPE = enable
+
+		return nil
+	}
+}
+
+// WithZRE enables the COBS/ZRE variant, zero run elimination, which encodes
+// a run of up to 48 zeros in a single code. This reduces the size of payloads
+// with long runs of zeros. Groups are limited to 207 bytes instead of 254.
+// Encoder and Decoder must agree on this setting. It can not be combined with
+// WithReduced or WithZPE, NewEncoder and NewDecoder then return
+// ErrIncompatibleOptions.
+func WithZRE(enable bool) Option {
+	return func(c *Config) error {
+		c.ZRE = enable
+
+		return nil
+	}
+}
+
+// WithSecondForbidden makes b a second byte value that never occurs in the
+// encoded data, besides the sentinel. Groups end on both a zero and b, the
+// code of a group tells which one was removed. Groups are limited to 126
+// bytes. Encoder and Decoder must agree on this setting. It can not be
+// combined with WithReduced, WithZPE or WithZRE, nor can b equal the
+// sentinel, NewEncoder and NewDecoder then return ErrIncompatibleOptions.
+func WithSecondForbidden(b byte) Option {
+	return func(c *Config) error {
+		c.SecondForbidden = true
+		c.Forbidden = b
+
+		return nil
+	}
+}
+
+// WithSentinel sets the byte value used as frame delimiter instead of 0x00.
+// The encoded data is XORed with the sentinel, so it never contains the
+// sentinel, which is written as delimiter. The Decoder reverses this.
+func WithSentinel(sentinel byte) Option {
+	return func(c *Config) error {
+		c.Sentinel = sentinel
+
+		return nil
+	}
+}
+
+// WithSentinelMode sets how the sentinel is kept out of the encoded data,
+// which defaults to SentinelXOR. Other implementations often stuff the
+// sentinel itself, the way 0x00 is stuffed, which SentinelNative does: data
+// bytes equal to the sentinel end a group and other bytes are written
+// unchanged. A code equal to the sentinel is written as 0x00 instead. With the
+// sentinel 0x00 both modes are plain COBS. Encoder and Decoder must agree on
+// this setting. SentinelNative can not be combined with WithSecondForbidden
+// or WithInsertedByte, NewEncoder and NewDecoder then return
+// ErrIncompatibleOptions. DecodeInPlace only handles SentinelXOR. Another
+// mode results in ErrInvalidOption.
+func WithSentinelMode(mode SentinelMode) Option {
+	return func(c *Config) error {
+		if mode != SentinelXOR && mode != SentinelNative {
+			return fmt.Errorf("%w: WithSentinelMode(%d)", ErrInvalidOption, mode)
+		}
+
+		c.SentinelMode = mode
+
+		return nil
+	}
+}
+
+// WithDelimiterSequence sets a sequence of bytes used as frame delimiter,
+// like "\r\n", for transports that expect frames to end with a sequence.
+// It does not free the first byte of seq for payload use: that byte becomes
+// the sentinel, like with WithSentinel, and never occurs in the encoded data,
+// so neither does seq. An Encoder writes seq wherever it writes a delimiter.
+// A Decoder ends a frame on the first byte and checks that the rest of seq
+// follows, otherwise a FrameError wrapping ErrInvalidDelimiter is returned.
+// Validate, DecodeInPlace and ScanFrames only handle single byte delimiters.
+// A later WithSentinel with another value results in ErrIncompatibleOptions,
+// an empty seq in ErrInvalidOption.
+func WithDelimiterSequence(seq []byte) Option {
+	seq = append([]byte(nil), seq...)
+
+	return func(c *Config) error {
+		if len(seq) == 0 {
+			return fmt.Errorf("%w: empty WithDelimiterSequence", ErrInvalidOption)
+		}
+
+		c.delimSeq = seq
+		c.Sentinel = seq[0]
+
+		return nil
+	}
+}
+
+// WithInsertedByte sets the payload byte that is removed by stuffing, which
+// an Encoder splits groups on and a Decoder inserts between groups. It
+// defaults to 0x00, which corresponds with the sentinel before it is applied.
+// With another value, payload bytes equal to 0x00 are no longer stuffed and
+// end up as sentinel in the encoded data, so this is only usable for payloads
+// that never contain 0x00.
+func WithInsertedByte(b byte) Option {
+	return func(c *Config) error {
+		c.InsertedByte = b
+
+		return nil
+	}
+}
+
+// WithDelimiterOnClose makes an Encoder write a delimiter when it is closed,
+// terminating the frame. Encode appends the delimiter to its output.
+func WithDelimiterOnClose(enable bool) Option {
+	return func(c *Config) error {
+		c.DelimiterOnClose = enable
+
+		return nil
+	}
+}
+
+// WithPrefixDelimiter makes an Encoder write a delimiter before each frame,
+// so a receiver that joins mid-stream synchronizes on the start of the next
+// frame. It is independent of WithDelimiterOnClose. A Decoder given this
+// option skips empty frames, like with WithSkipEmptyFrames.
+func WithPrefixDelimiter(enable bool) Option {
+	return func(c *Config) error {
+		c.PrefixDelimiter = enable
+
+		return nil
+	}
+}
+
+// WithCloseUnderlying makes Close of an Encoder or Decoder also close the
+// underlying io.Writer, if it implements io.Closer. An Encoder closes it after
+// writing the last group and delimiter, a Decoder after ending the frame. The
+// first error is returned. By default the io.Writer is not closed.
+func WithCloseUnderlying(enable bool) Option {
+	return func(c *Config) error {
+		c.CloseUnderlying = enable
+
+		return nil
+	}
+}
+
+// WithDiscardFirstPartial makes a Decoder drop all bytes up to and including
+// the first delimiter. This allows joining a running stream mid-frame, the
+// partial frame is discarded and decoding starts cleanly with the next frame.
+func WithDiscardFirstPartial(enable bool) Option {
+	return func(c *Config) error {
+		c.DiscardFirstPartial = enable
+
+		return nil
+	}
+}
+
+// WithSkipEmptyFrames makes a Decoder skip delimiters that are not preceded
+// by any byte of a frame, such as leading delimiters, double delimiters or
+// idle fill between frames. Such a delimiter does not return EOD, nor does it
+// call a frame handler or count as frame. Without this option it ends an empty
+// frame.
+func WithSkipEmptyFrames(enable bool) Option {
+	return func(c *Config) error {
+		c.SkipEmptyFrames = enable
+
+		return nil
+	}
+}
+
+// WithKeepAlive makes a Decoder call fn for every delimiter that is not
+// preceded by any byte of a frame, which a sender can write as keep-alive or
+// idle fill. Such a delimiter does not end an empty frame then, it does not
+// return EOD, nor does it call a frame handler or count as frame. An empty
+// frame that is encoded, as a single code byte, still decodes as frame, so
+// the two can be told apart. Write returns the error of fn, nil lets
+// decoding continue. This takes precedence over WithSkipEmptyFrames.
+// Transport, FrameConn, FrameChan, ReadFrames, Frames and DecodeFrames call
+// fn as well, even though the first two skip empty frames. Only FrameChan
+// reports keep-alives in its results, the others return or yield frames only.
+func WithKeepAlive(fn func() error) Option {
+	return func(c *Config) error {
+		c.keepAlive = fn
+
+		return nil
+	}
+}
+
+// WithMaxFrameSize limits the number of decoded bytes in a single frame to n.
+// A Decoder returns a FrameError wrapping ErrFrameTooLarge as soon as a frame
+// exceeds the limit. A value of n <= 0 means no limit, which is the default.
+func WithMaxFrameSize(n int) Option {
+	return func(c *Config) error {
+		c.MaxFrameSize = n
+
+		return nil
+	}
+}
+
+// WithMaxGroupSize limits groups to n data bytes instead of 254, so a
+// receiver can decode with a small fixed buffer per group. The code of a full
+// group becomes n+1, a Decoder returns a FrameError wrapping ErrInvalidCode
+// for a larger code. Encoder and Decoder must agree on this setting. A value
+// of n <= 0 or n >= 254 means no limit, which is the default. It can not be
+// combined with WithReduced, WithZPE, WithZRE or WithSecondForbidden,
+// NewEncoder and NewDecoder then return ErrIncompatibleOptions.
+func WithMaxGroupSize(n int) Option {
+	return func(c *Config) error {
+		c.MaxGroupSize = 0
+		if n > 0 && n < 254 {
+			c.MaxGroupSize = n
+		}
+
+		return nil
+	}
+}
+
+// fullCode returns the code of a full group of plain COBS, which is not
+// followed by a zero.
+func (c *Config) fullCode() byte {
+	if c.MaxGroupSize > 0 {
+		return byte(c.MaxGroupSize + 1)
+	}
+
+	return 0xff
+}
+
+// WithMaxGroupsPerFrame limits the number of groups in a single frame to n.
+// A Decoder returns a FrameError wrapping ErrTooManyGroups as soon as a frame
+// exceeds the limit. This bounds the work spent on frames of many tiny groups,
+// which a limit on the decoded size does not. A value of n <= 0 means no
+// limit, which is the default.
+func WithMaxGroupsPerFrame(n int) Option {
+	return func(c *Config) error {
+		c.MaxGroupsPerFrame = n
+
+		return nil
+	}
+}
+
+// WithStrict makes a Decoder check at each code byte that the delimiter does
+// not end the group early, as far as the data is available in the same call
+// of Write. Such a group results in a FrameError wrapping a GroupError, which
+// identifies the code, at the offset of the code byte. No data of the group
+// is forwarded. If the delimiter is written later, the same error is returned
+// for it. Frames that a conforming encoder never produces result in a
+// FrameError wrapping ErrNonCanonical, at the offset of the offending code:
+// an empty last group following a full group, and with WithZPE two zeros
+// that are not encoded as a pair. It has no effect with WithReduced, in which
+// the last group may end early.
+func WithStrict(enable bool) Option {
+	return func(c *Config) error {
+		c.Strict = enable
+
+		return nil
+	}
+}
+
+// WithResync makes a Decoder drop a frame with a FrameError, like
+// ErrUnexpectedEOD or an invalid group, and continue with the next frame
+// instead of returning the error. Input is discarded up to the next
+// delimiter, unless the error was detected at one. Dropped frames are
+// counted as errors in Stats and by WithExpvar. Data of a dropped frame that
+// was decoded before the error has already been forwarded. Errors of the
+// io.Writer or a frame handler are still returned.
+func WithResync(enable bool) Option {
+	return func(c *Config) error {
+		c.Resync = enable
+
+		return nil
+	}
+}
+
+// WithFrameTimeout limits the time a Decoder waits for a frame to complete
+// to d, counted from the first byte of the frame. The next write after the
+// limit is exceeded returns a FrameError wrapping ErrFrameTimeout, the frame
+// is dropped and input is discarded up to the next delimiter. With
+// WithResync this happens without returning the error. As a Decoder only
+// runs when written to, a Reader also sets a read deadline for the frame in
+// progress if its source supports it, like net.Conn and os.File, so a stalled
+// device can not block it forever. Set other read deadlines through
+// Reader.SetReadDeadline then. A value of d <= 0 means no limit, which is
+// the default.
+func WithFrameTimeout(d time.Duration) Option {
+	return func(c *Config) error {
+		c.FrameTimeout = d
+
+		return nil
+	}
+}
+
+// WithTypeRouter demultiplexes frames on their first decoded byte. A Decoder
+// calls fn with the type byte of each frame and writes the rest of the frame
+// to the returned writer instead of its own. If fn returns nil the rest of the
+// frame is discarded.
+func WithTypeRouter(fn func(typeByte byte) io.Writer) Option {
+	return func(c *Config) error {
+		c.typeRouter = fn
+
+		return nil
+	}
+}
+
+// WithFrameHandler makes a Decoder collect each frame and call fn with the
+// decoded frame once it is complete, either by a delimiter or Close. The
+// frame slice is reused and only valid during the call. Write returns the
+// error of fn instead of EOD, nil lets decoding continue with the next frame.
+// With a frame handler nothing is written to the io.Writer of the Decoder,
+// which may be nil, and WithTypeRouter has no effect. In COBS/R mode the last
+// byte of a frame is flushed before fn is called.
+func WithFrameHandler(fn func(frame []byte) error) Option {
+	return func(c *Config) error {
+		c.frameHandler = fn
+
+		return nil
+	}
+}
+
+// WithRateLimit paces the output of an Encoder to at most bytesPerSec bytes
+// per second, which prevents overrunning slow links such as a UART. Writes
+// block until the group may be sent. It only applies to streaming with an
+// Encoder, Encode ignores it. A value <= 0 disables pacing, the default.
+func WithRateLimit(bytesPerSec int) Option {
+	return func(c *Config) error {
+		c.RateLimit = bytesPerSec
+
+		return nil
+	}
+}
+
+// WithWriteBufferSize makes an Encoder collect up to n bytes of encoded data
+// before writing it, instead of writing every group right away. This reduces
+// the number of writes for large frames. The buffer is written when full and
+// by Close. A Decoder likewise collects up to n bytes of decoded data across
+// calls of Write, and writes them when full, at the end of each frame and by
+// Close. Output of WithTypeRouter is not buffered. A value of n <= 0
+// disables buffering, which is the default.
+func WithWriteBufferSize(n int) Option {
+	return func(c *Config) error {
+		c.WriteBufferSize = n
+
+		return nil
+	}
+}
+
+// withClock replaces the clock used for pacing.
+func withClock(clk clock) Option {
+	return func(c *Config) error {
+		c.clock = clk
+
+		return nil
+	}
+}
+
+// WithAppendNewline makes a Decoder write a newline after each complete frame,
+// which makes the output of line based protocols consumable by line oriented
+// tools. Frames that end with an error are not terminated by a newline.
+func WithAppendNewline(enable bool) Option {
+	return func(c *Config) error {
+		c.AppendNewline = enable
+
+		return nil
+	}
+}
+
+// WithAutoReset makes a Decoder continue with the next frame after the
+// delimiter ending a valid frame, instead of returning EOD. A single Write
+// then decodes any number of frames, which suits io.Copy. Errors still stop
+// decoding. See WithFrameSeparator to keep the frames apart in the output.
+func WithAutoReset(enable bool) Option {
+	return func(c *Config) error {
+		c.AutoReset = enable
+
+		return nil
+	}
+}
+
+// WithFrameSeparator makes a Decoder write sep after each complete frame,
+// following the newline of WithAppendNewline. Frames that end with an error
+// are not followed by sep.
+func WithFrameSeparator(sep []byte) Option {
+	sep = append([]byte(nil), sep...)
+
+	return func(c *Config) error {
+		c.separator = sep
+
+		return nil
+	}
+}
+
+// WithEODasEOF makes a Decoder return io.EOF instead of EOD at the delimiter
+// ending a valid frame, for callers that check for io.EOF. Like EOD it is not
+// an error, unlike ErrUnexpectedEOD for a malformed frame or
+// ErrIncompleteFrame. Note that io.Copy only treats io.EOF from a Read as
+// the end of data, it returns io.EOF from a Write of the Decoder.
+func WithEODasEOF(enable bool) Option {
+	return func(c *Config) error {
+		c.EODasEOF = enable
+
+		return nil
+	}
+}
+
+// WithChecksum appends a checksum of the given kind to the payload of each
+// frame before it is encoded, so the checksum is stuffed like the payload.
+// A Decoder verifies and strips the checksum, a frame with an invalid checksum
+// results in a FrameError wrapping ErrChecksumMismatch. A lone delimiter has
+// no checksum to verify and still decodes as an empty frame, keep-alive or
+// skipped delimiter. Encoder and Decoder must agree on this setting. The default is ChecksumNone, an unknown kind
+// results in ErrInvalidOption.
+func WithChecksum(kind ChecksumKind) Option {
+	return func(c *Config) error {
+		if kind < ChecksumNone || kind > CRC32 {
+			return fmt.Errorf("%w: WithChecksum(%d)", ErrInvalidOption, kind)
+		}
+
+		c.Checksum = kind
+
+		return nil
+	}
+}
+
+// WithHistogram makes an Encoder or Decoder add every frame to h, see
+// Histogram. By default frames are not recorded.
+func WithHistogram(h *Histogram) Option {
+	return func(c *Config) error {
+		c.histogram = h
+
+		return nil
+	}
+}
+
+// WithMetrics makes an Encoder or Decoder report its frames, errors and
+// resynchronizations to m, see Metrics. By default nothing is reported.
+func WithMetrics(m Metrics) Option {
+	return func(c *Config) error {
+		c.metrics = m
+
+		return nil
+	}
+}
+
+// WithExpvar publishes the counters of a Decoder with expvar, as integers
+// named prefix followed by ".frames", ".bytes" and ".errors". They count the
+// complete frames, their decoded bytes and the frame errors. Decoders using
+// the same prefix share the counters. Nothing is published by default.
+func WithExpvar(prefix string) Option {
+	return func(c *Config) error {
+		c.Expvar = prefix
+
+		return nil
+	}
+}
+
+// WithLengthPrefix prepends the length of the payload of each frame as an
+// unsigned varint before it is encoded, so the prefix is stuffed like the
+// payload. An Encoder buffers each frame until Close to know its length.
+// A Decoder strips the prefix and any bytes beyond the length, like the
+// padding of WithMinPayload. A frame that is shorter than its length results
+// in a FrameError wrapping ErrInvalidLength. Encoder and Decoder must agree on
+// this setting.
+func WithLengthPrefix(enable bool) Option {
+	return func(c *Config) error {
+		c.LengthPrefix = enable
+
+		return nil
+	}
+}
+
+// WithSequence makes an Encoder start each frame with a sequence number, a
+// byte that increments with every frame, wrapping around after 255. A
+// Decoder strips it and compares it with the previous frame, counting lost
+// and duplicate frames in Stats; see LastSequence. Reordered frames count as
+// lost. A frame without sequence number results in a FrameError wrapping
+// ErrIncompleteFrame. Encoder and Decoder must agree on this setting.
+func WithSequence(enable bool) Option {
+	return func(c *Config) error {
+		c.Sequence = enable
+
+		return nil
+	}
+}
+
+// WithMinPayload makes an Encoder append pad bytes to payloads shorter than
+// n bytes before they are encoded. As COBS does not carry the length of the
+// payload, a Decoder only strips the padding combined with WithLengthPrefix.
+func WithMinPayload(n int, pad byte) Option {
+	return func(c *Config) error {
+		c.MinPayload = n
+		c.Pad = pad
+
+		return nil
+	}
+}
@@ -0,0 +1,95 @@
+package cobs
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+var errCustom = errors.New("custom option")
+
+func TestCheckOptions(t *testing.T) {
+	testCases := []struct {
+		name string
+		opts []Option
+		err  error
+	}{
+		{name: "None"},
+		{name: "Compatible", opts: []Option{WithReduced(true), WithSentinel(0x42)}},
+		{name: "ZPE and reduced", opts: []Option{WithZPE(true), WithReduced(true)}, err: ErrIncompatibleOptions},
+		{name: "Second and sentinel", opts: []Option{WithSecondForbidden(0x42), WithSentinel(0x42)}, err: ErrIncompatibleOptions},
+		{name: "Sentinel mode", opts: []Option{WithSentinelMode(SentinelMode(7))}, err: ErrInvalidOption},
+		{name: "Checksum", opts: []Option{WithChecksum(ChecksumKind(-1))}, err: ErrInvalidOption},
+		{name: "Delimiter sequence", opts: []Option{WithDelimiterSequence(nil)}, err: ErrInvalidOption},
+		{name: "Custom", opts: []Option{func(c *Config) error { return errCustom }}, err: errCustom},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := CheckOptions(tc.opts...)
+			if !errors.Is(err, tc.err) {
+				t.Errorf("got %v, want %v", err, tc.err)
+			}
+
+			// Constructors and helpers report the same error
+			if _, err := NewEncoder(io.Discard, tc.opts...); !errors.Is(err, tc.err) {
+				t.Errorf("new encoder got %v, want %v", err, tc.err)
+			}
+			if _, err := NewDecoder(io.Discard, tc.opts...); !errors.Is(err, tc.err) {
+				t.Errorf("new decoder got %v, want %v", err, tc.err)
+			}
+			if _, err := Encode([]byte("a"), tc.opts...); !errors.Is(err, tc.err) {
+				t.Errorf("encode got %v, want %v", err, tc.err)
+			}
+		})
+	}
+}
+
+// mustEncoder returns NewEncoder(w, opts...), failing t on an error.
+func mustEncoder(t testing.TB, w io.Writer, opts ...Option) *Encoder {
+	t.Helper()
+
+	e, err := NewEncoder(w, opts...)
+	if err != nil {
+		t.Fatalf("new encoder: %v", err)
+	}
+
+	return e
+}
+
+// mustDecoder returns NewDecoder(w, opts...), failing t on an error.
+func mustDecoder(t testing.TB, w io.Writer, opts ...Option) *Decoder {
+	t.Helper()
+
+	d, err := NewDecoder(w, opts...)
+	if err != nil {
+		t.Fatalf("new decoder: %v", err)
+	}
+
+	return d
+}
+
+// withPreset is an option constructor like users can write, combining
+// settings of Config.
+func withPreset() Option {
+	return func(c *Config) error {
+		c.Reduced = true
+		c.Sentinel = 0x42
+		c.DelimiterOnClose = true
+
+		return nil
+	}
+}
+
+func TestCustomOption(t *testing.T) {
+	data := []byte("Hello\x00world")
+
+	got, err := Encode(data, withPreset())
+	if err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+	want, _ := Encode(data, WithReduced(true), WithSentinel(0x42), WithDelimiterOnClose(true))
+	if string(got) != string(want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
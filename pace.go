@@ -0,0 +1,36 @@
+package cobs
+
+import "time"
+
+// A clock provides the time for pacing, it can be replaced in tests.
+type clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time        { return time.Now() }
+func (realClock) Sleep(d time.Duration) { time.Sleep(d) }
+
+// A pacer spaces writes so the average rate does not exceed a number of
+// bytes per second. Idle time does not build up credit for a later burst.
+type pacer struct {
+	rate  int
+	clock clock
+	next  time.Time
+}
+
+// wait blocks until n bytes may be written.
+func (p *pacer) wait(n int) {
+	now := p.clock.Now()
+	if p.next.Before(now) {
+		p.next = now
+	}
+
+	if d := p.next.Sub(now); d > 0 {
+		p.clock.Sleep(d)
+	}
+
+	p.next = p.next.Add(time.Duration(n) * time.Second / time.Duration(p.rate))
+}
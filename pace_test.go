@@ -0,0 +1,57 @@
+package cobs
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.sleeps = append(c.sleeps, d)
+	c.now = c.now.Add(d)
+}
+
+func TestRateLimit(t *testing.T) {
+	clk := &fakeClock{now: time.Unix(0, 0)}
+
+	var buf bytes.Buffer
+	e := mustEncoder(t, &buf, WithRateLimit(100), withClock(clk))
+
+	// Groups of 4 and 3 bytes are written
+	if _, err := e.Write([]byte("abc\x00de\x00")); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+
+	// Idle time does not allow a burst
+	clk.now = clk.now.Add(time.Second)
+
+	if _, err := e.Write([]byte("f\x00")); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatalf("close error: %v", err)
+	}
+
+	want := []time.Duration{40 * time.Millisecond, 20 * time.Millisecond}
+	if len(clk.sleeps) != len(want) {
+		t.Fatalf("got sleeps %v, want %v", clk.sleeps, want)
+	}
+	for i := range want {
+		if clk.sleeps[i] != want[i] {
+			t.Errorf("sleep %d got %v, want %v", i, clk.sleeps[i], want[i])
+		}
+	}
+
+	if got := buf.String(); got != "\x04abc\x03de\x02f\x01" {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,40 @@
+package cobs
+
+// A Packet is a frame of data that implements encoding.BinaryMarshaler and
+// encoding.BinaryUnmarshaler with its encoding.
+type Packet struct {
+	Data     []byte
+	Sentinel byte
+	Reduced  bool
+}
+
+func (p Packet) options() []Option {
+	return []Option{WithSentinel(p.Sentinel), WithReduced(p.Reduced)}
+}
+
+// MarshalBinary returns the encoded frame of Data, terminated by a delimiter.
+func (p Packet) MarshalBinary() ([]byte, error) {
+	return Encode(p.Data, append(p.options(), WithDelimiterOnClose(true))...)
+}
+
+// UnmarshalBinary decodes a single frame, optionally terminated by a
+// delimiter, into Data, using the Sentinel and Reduced settings of p. For
+// malformed data a FrameError wrapping ErrUnexpectedEOD or ErrIncompleteFrame
+// is returned, as by Validate, and Data is left unchanged.
+func (p *Packet) UnmarshalBinary(data []byte) error {
+	if err := Validate(data, p.options()...); err != nil {
+		return err
+	}
+
+	if n := len(data); data[n-1] == p.Sentinel {
+		data = data[:n-1]
+	}
+
+	dec, err := Decode(data, p.options()...)
+	if err != nil {
+		return err
+	}
+	p.Data = dec
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package cobs
+
+import (
+	"bytes"
+	"encoding"
+	"errors"
+	"testing"
+)
+
+var (
+	_ encoding.BinaryMarshaler   = Packet{}
+	_ encoding.BinaryUnmarshaler = &Packet{}
+)
+
+func TestPacket(t *testing.T) {
+	for _, tc := range testCases {
+		for _, p := range []Packet{{Data: tc.dec}, {Data: tc.dec, Sentinel: 0x42, Reduced: true}} {
+			enc, err := p.MarshalBinary()
+			if err != nil {
+				t.Fatalf("%s: marshal error: %v", tc.name, err)
+			}
+			if enc[len(enc)-1] != p.Sentinel {
+				t.Errorf("%s: %v is not terminated", tc.name, enc)
+			}
+
+			// With and without delimiter
+			for _, data := range [][]byte{enc, enc[:len(enc)-1]} {
+				got := Packet{Sentinel: p.Sentinel, Reduced: p.Reduced}
+				if err := got.UnmarshalBinary(data); err != nil {
+					t.Fatalf("%s: unmarshal error: %v", tc.name, err)
+				}
+				if !bytes.Equal(got.Data, tc.dec) {
+					t.Errorf("%s: got %v, want %v", tc.name, got.Data, tc.dec)
+				}
+			}
+		}
+	}
+}
+
+func TestPacketErrors(t *testing.T) {
+	for _, tc := range unexpectedDelimiter {
+		var p Packet
+		if err := p.UnmarshalBinary(tc.enc); !errors.Is(err, ErrUnexpectedEOD) {
+			t.Errorf("%s: got %v, want %v", tc.name, err, ErrUnexpectedEOD)
+		}
+	}
+
+	for _, tc := range incompleteFrame {
+		var p Packet
+		if err := p.UnmarshalBinary(tc.enc); !errors.Is(err, ErrIncompleteFrame) {
+			t.Errorf("%s: got %v, want %v", tc.name, err, ErrIncompleteFrame)
+		}
+	}
+}
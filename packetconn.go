@@ -0,0 +1,69 @@
+package cobs
+
+import (
+	"net"
+	"time"
+)
+
+// A PacketConn is a net.PacketConn that exchanges datagrams as frames over a
+// stream, like a TCP connection or a serial port, so datagram protocols can
+// run over it unchanged. Every datagram comes from and goes to the remote
+// address of the stream.
+type PacketConn struct {
+	fc *FrameConn
+}
+
+var _ net.PacketConn = (*PacketConn)(nil)
+
+// NewPacketConn returns a PacketConn that sends and receives datagrams over
+// conn, as frames of a FrameConn created with the options.
+func NewPacketConn(conn net.Conn, opts ...Option) *PacketConn {
+	return &PacketConn{fc: NewFrameConn(conn, opts...)}
+}
+
+// ReadFrom reads the next datagram into p and returns its length and the
+// remote address. Like a UDP connection, the bytes of a datagram that do not
+// fit in p are discarded.
+func (c *PacketConn) ReadFrom(p []byte) (int, net.Addr, error) {
+	frame, err := c.fc.ReadFrame()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return copy(p, frame), c.fc.RemoteAddr(), nil
+}
+
+// WriteTo writes p as a single datagram. The stream has a single peer, so
+// addr is not used.
+func (c *PacketConn) WriteTo(p []byte, addr net.Addr) (int, error) {
+	if err := c.fc.WriteFrame(p); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Close closes the stream.
+func (c *PacketConn) Close() error {
+	return c.fc.Close()
+}
+
+// LocalAddr returns the local network address of the stream.
+func (c *PacketConn) LocalAddr() net.Addr {
+	return c.fc.LocalAddr()
+}
+
+// SetDeadline sets the read and write deadlines of the stream.
+func (c *PacketConn) SetDeadline(t time.Time) error {
+	return c.fc.SetDeadline(t)
+}
+
+// SetReadDeadline sets the deadline for ReadFrom.
+func (c *PacketConn) SetReadDeadline(t time.Time) error {
+	return c.fc.SetReadDeadline(t)
+}
+
+// SetWriteDeadline sets the deadline for WriteTo.
+func (c *PacketConn) SetWriteDeadline(t time.Time) error {
+	return c.fc.SetWriteDeadline(t)
+}
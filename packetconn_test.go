@@ -0,0 +1,41 @@
+package cobs
+
+import (
+	"net"
+	"testing"
+)
+
+func TestPacketConn(t *testing.T) {
+	c1, c2 := net.Pipe()
+	a := NewPacketConn(c1)
+	b := NewPacketConn(c2)
+	defer a.Close()
+	defer b.Close()
+
+	go func() {
+		_, _ = b.WriteTo([]byte("ping\x00"), nil)
+		_, _ = b.WriteTo([]byte("a long datagram"), nil)
+	}()
+
+	buf := make([]byte, 8)
+
+	n, addr, err := a.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(buf[:n]) != "ping\x00" {
+		t.Errorf("got %q, want %q", buf[:n], "ping\x00")
+	}
+	if addr != c1.RemoteAddr() {
+		t.Errorf("got address %v, want %v", addr, c1.RemoteAddr())
+	}
+
+	// The rest of a datagram that does not fit is discarded
+	n, _, err = a.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(buf[:n]) != "a long d" {
+		t.Errorf("got %q, want %q", buf[:n], "a long d")
+	}
+}
@@ -0,0 +1,15 @@
+package cobs
+
+import "io"
+
+// Pipe returns an Encoder that writes its encoded data to a Decoder, which
+// writes the decoded data to dst. What is written to the Encoder ends up in
+// dst unchanged, after passing through both encoding and decoding. Closing
+// the Encoder also closes the Decoder, which validates the frame, so each
+// frame has to be ended by Close. The options apply to both, except that no
+// delimiter is written.
+func Pipe(dst io.Writer, opts ...Option) *Encoder {
+	opts = append(opts[:len(opts):len(opts)], WithDelimiterOnClose(false))
+
+	return newEncoder(newDecoder(dst, opts...), append(opts, WithCloseUnderlying(true))...)
+}
@@ -0,0 +1,40 @@
+package cobs
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestPipe(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithReduced(true)}, {WithChecksum(CRC8), WithSentinel(0x42)}} {
+		var buf bytes.Buffer
+		e := Pipe(&buf, opts...)
+
+		var want []byte
+		for _, tc := range testCases {
+			if _, err := e.Write(tc.dec); err != nil {
+				t.Fatalf("%s: write error: %v", tc.name, err)
+			}
+			if err := e.Close(); err != nil {
+				t.Fatalf("%s: close error: %v", tc.name, err)
+			}
+			want = append(want, tc.dec...)
+		}
+
+		if !bytes.Equal(buf.Bytes(), want) {
+			t.Errorf("got %v, want %v", buf.Bytes(), want)
+		}
+	}
+}
+
+func TestPipeError(t *testing.T) {
+	e := Pipe(&bytes.Buffer{}, WithMaxFrameSize(2))
+
+	if _, err := e.Write([]byte("abc")); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	if err := e.Close(); !errors.Is(err, ErrFrameTooLarge) {
+		t.Errorf("got %v, want %v", err, ErrFrameTooLarge)
+	}
+}
@@ -0,0 +1,7 @@
+//go:build race
+
+package cobs
+
+// raceEnabled reports whether the race detector is on, which makes pooled
+// objects and escape analysis unreliable for allocation counts.
+const raceEnabled = true
@@ -0,0 +1,170 @@
+package cobs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+// A Reader reads encoded frames from an underlying io.Reader and returns the
+// decoded data on Read. The data of consecutive frames is concatenated, see
+// DecodeFrames for frames that have to be kept apart.
+type Reader struct {
+	r   io.Reader
+	d   *Decoder
+	out bytes.Buffer
+	buf []byte
+	err error
+
+	deadline time.Time
+}
+
+// NewReader returns a Reader that decodes the data read from r. A last frame
+// that is not terminated by a delimiter is ended as by Decoder.Close.
+func NewReader(r io.Reader, opts ...Option) *Reader {
+	rd := &Reader{
+		r:   r,
+		buf: make([]byte, 4096),
+	}
+	rd.d = newDecoder(&rd.out, append(opts[:len(opts):len(opts)], WithEODasEOF(false), WithAutoReset(false))...)
+
+	return rd
+}
+
+// Read reads decoded data into p. Errors decoding a frame are returned once
+// the data decoded before is read, and are returned by all further calls.
+func (r *Reader) Read(p []byte) (int, error) {
+	for r.out.Len() == 0 && r.err == nil {
+		r.fill()
+	}
+
+	if r.out.Len() > 0 {
+		return r.out.Read(p)
+	}
+
+	return 0, r.err
+}
+
+// SetReadDeadline sets the read deadline of the underlying reader, which
+// returns os.ErrNoDeadline if it has none. With WithFrameTimeout, the deadline
+// has to be set through the Reader, which shortens it while a frame is in
+// progress and puts it back afterwards.
+func (r *Reader) SetReadDeadline(t time.Time) error {
+	rd, ok := r.r.(readDeadliner)
+	if !ok {
+		return os.ErrNoDeadline
+	}
+
+	r.deadline = t
+
+	return rd.SetReadDeadline(t)
+}
+
+// fill decodes the next chunk of input.
+func (r *Reader) fill() {
+	rd, ok := r.r.(readDeadliner)
+	frame := r.d.frameDeadline()
+	timed := ok && !frame.IsZero()
+	if timed {
+		if !r.deadline.IsZero() && r.deadline.Before(frame) {
+			frame = r.deadline
+		}
+		_ = rd.SetReadDeadline(frame)
+	}
+
+	n, err := r.r.Read(r.buf)
+	if timed {
+		_ = rd.SetReadDeadline(r.deadline)
+	}
+
+	// The read deadline of a frame passed
+	if errors.Is(err, os.ErrDeadlineExceeded) && r.d.timeout > 0 {
+		if terr := r.d.checkTimeout(); terr != nil {
+			if !r.d.resync {
+				r.err = terr
+				return
+			}
+			err = nil
+		}
+	}
+
+	for p := r.buf[:n]; len(p) > 0; {
+		m, derr := r.d.Write(p)
+		if derr == nil {
+			break
+		}
+		if derr != EOD {
+			r.err = derr
+			return
+		}
+
+		p = p[m+1:]
+	}
+
+	switch {
+	case err == io.EOF:
+		r.err = io.EOF
+		if cerr := r.d.Close(); cerr != nil {
+			r.err = cerr
+		}
+	case err != nil:
+		r.err = err
+	}
+}
+
+// An EncodingReader reads data from an underlying io.Reader and returns it
+// encoded as a single frame on Read.
+type EncodingReader struct {
+	r   io.Reader
+	e   *Encoder
+	out bytes.Buffer
+	buf []byte
+	err error
+}
+
+// NewEncodingReader returns an EncodingReader that encodes the data read from
+// r. Once r returns io.EOF, the frame is ended and terminated by a delimiter.
+func NewEncodingReader(r io.Reader, opts ...Option) *EncodingReader {
+	er := &EncodingReader{
+		r:   r,
+		buf: make([]byte, 4096),
+	}
+	er.e = newEncoder(&er.out, append(opts[:len(opts):len(opts)], WithDelimiterOnClose(true))...)
+
+	return er
+}
+
+// Read reads encoded data into p.
+func (r *EncodingReader) Read(p []byte) (int, error) {
+	for r.out.Len() == 0 && r.err == nil {
+		r.fill()
+	}
+
+	if r.out.Len() > 0 {
+		return r.out.Read(p)
+	}
+
+	return 0, r.err
+}
+
+// fill encodes the next chunk of input.
+func (r *EncodingReader) fill() {
+	n, err := r.r.Read(r.buf)
+
+	if _, werr := r.e.Write(r.buf[:n]); werr != nil {
+		r.err = werr
+		return
+	}
+
+	switch {
+	case err == io.EOF:
+		r.err = io.EOF
+		if cerr := r.e.Close(); cerr != nil {
+			r.err = cerr
+		}
+	case err != nil:
+		r.err = err
+	}
+}
@@ -0,0 +1,84 @@
+package cobs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReader(t *testing.T) {
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// One byte at a time, with and without a delimiter
+			for _, enc := range [][]byte{tc.enc, append(tc.enc[:len(tc.enc):len(tc.enc)], Delimiter)} {
+				got, err := io.ReadAll(NewReader(iotest.OneByteReader(bytes.NewReader(enc))))
+				if err != nil {
+					t.Fatalf("read error: %v", err)
+				}
+				if !bytes.Equal(got, tc.dec) {
+					t.Errorf("got %v, want %v", got, tc.dec)
+				}
+			}
+		})
+	}
+}
+
+func TestReaderFrames(t *testing.T) {
+	r := NewReader(bytes.NewReader([]byte("\x06Hello\x00\x00\x08, world")))
+
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("read error: %v", err)
+	}
+	if string(got) != "Hello, world" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestReaderError(t *testing.T) {
+	r := NewReader(bytes.NewReader([]byte("\x02a\x00\x06Hel\x00\x02b\x00")))
+
+	got, err := io.ReadAll(r)
+	if !errors.Is(err, ErrUnexpectedEOD) {
+		t.Errorf("got %v, want %v", err, ErrUnexpectedEOD)
+	}
+	if string(got) != "aHel" {
+		t.Errorf("got %q, want %q", got, "aHel")
+	}
+
+	if _, err := r.Read(make([]byte, 1)); !errors.Is(err, ErrUnexpectedEOD) {
+		t.Errorf("got %v, want %v", err, ErrUnexpectedEOD)
+	}
+}
+
+func TestEncodingReader(t *testing.T) {
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewEncodingReader(iotest.OneByteReader(bytes.NewReader(tc.dec)))
+
+			got, err := io.ReadAll(iotest.OneByteReader(r))
+			if err != nil {
+				t.Fatalf("read error: %v", err)
+			}
+
+			want := append(tc.enc[:len(tc.enc):len(tc.enc)], Delimiter)
+			if !bytes.Equal(got, want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestEncodingReaderRoundTrip(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789\x00"), 1000)
+
+	got, err := io.ReadAll(NewReader(NewEncodingReader(bytes.NewReader(data))))
+	if err != nil {
+		t.Fatalf("read error: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("round trip differs")
+	}
+}
@@ -0,0 +1,59 @@
+package cobs
+
+import (
+	"bufio"
+	"io"
+)
+
+// RepairStats reports the number of frames kept and dropped by Repair.
+type RepairStats struct {
+	Kept    int
+	Dropped int
+}
+
+// Repair reads a stream of delimiter terminated frames from src and writes
+// only the valid frames to dst, re-encoded and delimiter terminated. Malformed
+// frames and a truncated last frame are dropped, decoding resumes with the
+// next frame. Empty frames between consecutive delimiters are skipped without
+// being counted. Errors reading src or writing dst are returned.
+func Repair(src io.Reader, dst io.Writer, opts ...Option) (RepairStats, error) {
+	var stats RepairStats
+
+	c := newConfig(opts)
+	r := bufio.NewReader(src)
+	encOpts := append(opts[:len(opts):len(opts)], WithDelimiterOnClose(true))
+
+	for {
+		chunk, err := r.ReadBytes(c.Sentinel)
+		if err == io.EOF {
+			if len(chunk) > 0 {
+				stats.Dropped++
+			}
+
+			return stats, nil
+		}
+		if err != nil {
+			return stats, err
+		}
+
+		if len(chunk) == 1 {
+			continue
+		}
+
+		dec, err := Decode(chunk[:len(chunk)-1], opts...)
+		if err != nil {
+			stats.Dropped++
+			continue
+		}
+
+		enc, err := Encode(dec, encOpts...)
+		if err != nil {
+			return stats, err
+		}
+
+		if _, err := dst.Write(enc); err != nil {
+			return stats, err
+		}
+		stats.Kept++
+	}
+}
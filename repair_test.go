@@ -0,0 +1,31 @@
+package cobs
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRepair(t *testing.T) {
+	input := []byte("\x02a\x00" + // valid
+		"\x05ab\x00" + // unexpected delimiter
+		"\x00" + // idle delimiter
+		"\x03bc\x00" + // valid
+		"\xff\x00" + // unexpected delimiter
+		"\x02d\x00" + // valid
+		"\x04ef") // truncated
+
+	var out bytes.Buffer
+	stats, err := Repair(bytes.NewReader(input), &out)
+	if err != nil {
+		t.Fatalf("repair error: %v", err)
+	}
+
+	want := RepairStats{Kept: 3, Dropped: 3}
+	if stats != want {
+		t.Errorf("got stats %+v, want %+v", stats, want)
+	}
+
+	if got := out.String(); got != "\x02a\x00\x03bc\x00\x02d\x00" {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,19 @@
+package cobs
+
+// resyncFrame drops the current frame after a frame error if WithResync is
+// enabled, and reports whether it did. Unless the error was detected at a
+// delimiter, input is discarded up to the next one. A lookahead error is
+// detected before c is decoded.
+func (d *Decoder) resyncFrame(err error, c byte, lookahead bool) bool {
+	if _, ok := err.(*FrameError); !ok || !d.resync {
+		return false
+	}
+
+	d.reset()
+	d.discard = lookahead || c^d.sentinel != Delimiter
+	if d.metrics != nil {
+		d.metrics.Resync()
+	}
+
+	return true
+}
@@ -0,0 +1,72 @@
+package cobs
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestResync(t *testing.T) {
+	testCases := []struct {
+		name string
+		enc  []byte
+		opts []Option
+		want string
+		// Byte by byte, if different
+		single string
+	}{
+		{
+			name: "Unexpected delimiter",
+			enc:  []byte("\x02a\x00\x05bc\x00\x02d\x00"),
+			want: "a|bcd|",
+		},
+		{
+			name: "Invalid code",
+			enc:  []byte("\x02a\x00\x02b\x7fc\x00\x02d\x00"),
+			opts: []Option{WithSecondForbidden(0x7f)},
+			want: "a|bd|",
+		},
+		{
+			name:   "Strict group",
+			enc:    []byte("\x02a\x00\x05bc\x00\x02d\x00"),
+			opts:   []Option{WithStrict(true)},
+			want:   "a|d|",
+			single: "a|bcd|",
+		},
+		{
+			name: "Frame too large",
+			enc:  []byte("\x02a\x00\x05bcde\x02f\x00\x02g\x00"),
+			opts: []Option{WithMaxFrameSize(2)},
+			want: "a|bcg|",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := append(tc.opts, WithResync(true), WithAutoReset(true), WithFrameSeparator([]byte("|")))
+
+			// Data decoded before an error is forwarded
+			var bulk, single bytes.Buffer
+			if _, err := mustDecoder(t, &bulk, opts...).Write(tc.enc); err != nil {
+				t.Fatalf("write error: %v", err)
+			}
+			d := mustDecoder(t, &single, opts...)
+			if _, err := writeBytes(d, tc.enc); err != nil {
+				t.Fatalf("write error: %v", err)
+			}
+
+			if got := bulk.String(); got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+			want := tc.single
+			if want == "" {
+				want = tc.want
+			}
+			if got := single.String(); got != want {
+				t.Errorf("byte by byte got %q, want %q", got, want)
+			}
+			if s := d.Stats(); s.Frames != 2 || s.Errors != 1 {
+				t.Errorf("got %d frames, %d errors, want 2 frames, 1 error", s.Frames, s.Errors)
+			}
+		})
+	}
+}
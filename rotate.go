@@ -0,0 +1,110 @@
+package cobs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// RotatingFrameWriter writes encoded, delimiter terminated frames to a
+// sequence of files in a directory, starting a new file when the current one
+// would exceed a maximum size. A frame is never split across files.
+type RotatingFrameWriter struct {
+	dir      string
+	maxBytes int64
+	opts     []Option
+	f        *os.File
+	size     int64
+	index    int
+	resumed  bool
+}
+
+// NewRotatingFrameWriter returns a RotatingFrameWriter that writes to files
+// named frames-000000.cobs, frames-000001.cobs and so on in dir. A file is
+// rotated when the next frame would make it larger than maxBytes, a single
+// frame larger than maxBytes gets a file of its own. Numbering continues
+// after the highest numbered file already in dir, so the files of an earlier
+// run are never overwritten. The options are passed to the Encoder, a
+// delimiter is always written after each frame.
+func NewRotatingFrameWriter(dir string, maxBytes int64, opts ...Option) *RotatingFrameWriter {
+	return &RotatingFrameWriter{
+		dir:      dir,
+		maxBytes: maxBytes,
+		opts:     append(opts[:len(opts):len(opts)], WithDelimiterOnClose(true), WithRateLimit(0)),
+	}
+}
+
+// WriteFrame encodes frame and writes it to the current file, rotating to a
+// new file first if needed.
+func (r *RotatingFrameWriter) WriteFrame(frame []byte) error {
+	enc, err := Encode(frame, r.opts...)
+	if err != nil {
+		return err
+	}
+
+	if r.f != nil && r.size > 0 && r.size+int64(len(enc)) > r.maxBytes {
+		if err := r.Close(); err != nil {
+			return err
+		}
+	}
+
+	if r.f == nil {
+		if err := r.open(); err != nil {
+			return err
+		}
+	}
+
+	n, err := r.f.Write(enc)
+	r.size += int64(n)
+
+	return err
+}
+
+// open creates the next file. The first one follows the highest numbered
+// file in dir, files that exist are skipped.
+func (r *RotatingFrameWriter) open() error {
+	if !r.resumed {
+		entries, err := os.ReadDir(r.dir)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			var i int
+			if _, err := fmt.Sscanf(entry.Name(), "frames-%d.cobs", &i); err == nil && i >= r.index {
+				r.index = i + 1
+			}
+		}
+		r.resumed = true
+	}
+
+	for {
+		name := filepath.Join(r.dir, fmt.Sprintf("frames-%06d.cobs", r.index))
+		r.index++
+
+		f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		r.f = f
+		r.size = 0
+
+		return nil
+	}
+}
+
+// Close closes the current file, a following WriteFrame starts a new file.
+func (r *RotatingFrameWriter) Close() error {
+	if r.f == nil {
+		return nil
+	}
+
+	err := r.f.Close()
+	r.f = nil
+
+	return err
+}
@@ -0,0 +1,96 @@
+package cobs
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRotatingFrameWriter(t *testing.T) {
+	dir := t.TempDir()
+	w := NewRotatingFrameWriter(dir, 16)
+
+	frames := [][]byte{
+		[]byte("12345"),
+		[]byte("678\x009"),
+		[]byte("abcdef"),
+		[]byte("this frame is larger than a file"),
+		[]byte("g"),
+	}
+	for _, frame := range frames {
+		if err := w.WriteFrame(frame); err != nil {
+			t.Fatalf("write frame error: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close error: %v", err)
+	}
+
+	names, err := filepath.Glob(filepath.Join(dir, "frames-*.cobs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 4 {
+		t.Fatalf("got %d files, want 4", len(names))
+	}
+
+	var got [][]byte
+	for _, name := range names {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) > 16 && bytes.Count(data, []byte{Delimiter}) > 1 {
+			t.Errorf("%s: %d bytes exceeds limit", name, len(data))
+		}
+
+		// Each file holds whole frames only
+		dec, err := DecodeFrames(data)
+		if err != nil {
+			t.Fatalf("%s: decode error: %v", name, err)
+		}
+		got = append(got, dec...)
+	}
+
+	if len(got) != len(frames) {
+		t.Fatalf("got %d frames, want %d", len(got), len(frames))
+	}
+	for i := range frames {
+		if !bytes.Equal(got[i], frames[i]) {
+			t.Errorf("frame %d: got %q, want %q", i, got[i], frames[i])
+		}
+	}
+}
+
+func TestRotatingFrameWriterRestart(t *testing.T) {
+	dir := t.TempDir()
+
+	// Each run writes a file, a restart keeps the earlier ones
+	for run := 0; run < 3; run++ {
+		w := NewRotatingFrameWriter(dir, 16)
+		if err := w.WriteFrame([]byte{'a' + byte(run)}); err != nil {
+			t.Fatalf("run %d: write frame error: %v", run, err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("run %d: close error: %v", run, err)
+		}
+	}
+
+	names, err := filepath.Glob(filepath.Join(dir, "frames-*.cobs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 3 {
+		t.Fatalf("got %d files, want 3", len(names))
+	}
+	for i, name := range names {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := string([]byte{0x02, 'a' + byte(i), Delimiter}); string(data) != want {
+			t.Errorf("%s: got %q, want %q", name, data, want)
+		}
+	}
+}
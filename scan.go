@@ -0,0 +1,31 @@
+package cobs
+
+import (
+	"bufio"
+	"bytes"
+)
+
+// ScanFrames returns a split function for a bufio.Scanner that returns each
+// encoded frame terminated by sentinel, without the delimiter. Empty frames,
+// such as double delimiters or idle fill, are skipped. At the end of the
+// input a last frame without delimiter is returned as well, decoding it
+// reports whether it is incomplete.
+func ScanFrames(sentinel byte) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		start := 0
+		for start < len(data) && data[start] == sentinel {
+			start++
+		}
+
+		if i := bytes.IndexByte(data[start:], sentinel); i >= 0 {
+			return start + i + 1, data[start : start+i], nil
+		}
+
+		if atEOF && start < len(data) {
+			return len(data), data[start:], nil
+		}
+
+		// Request more data, dropping the skipped delimiters
+		return start, nil, nil
+	}
+}
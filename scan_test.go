@@ -0,0 +1,49 @@
+package cobs
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"testing/iotest"
+)
+
+func TestScanFrames(t *testing.T) {
+	frames := [][]byte{[]byte("Hello"), {0x00}, bytes.Repeat([]byte{0x01}, 300)}
+
+	for _, sentinel := range []byte{0x00, 0x42} {
+		enc, err := EncodeFrames(frames, WithSentinel(sentinel))
+		if err != nil {
+			t.Fatalf("encode error: %v", err)
+		}
+
+		// Leading and double delimiters are skipped, the last frame is unterminated
+		stream := append([]byte{sentinel}, enc...)
+		stream = append(stream, sentinel)
+		stream = append(stream, enc[:len(enc)-1]...)
+
+		s := bufio.NewScanner(iotest.OneByteReader(bytes.NewReader(stream)))
+		s.Split(ScanFrames(sentinel))
+
+		var got [][]byte
+		for s.Scan() {
+			dec, err := Decode(s.Bytes(), WithSentinel(sentinel))
+			if err != nil {
+				t.Fatalf("decode error: %v", err)
+			}
+			got = append(got, dec)
+		}
+		if err := s.Err(); err != nil {
+			t.Fatalf("scan error: %v", err)
+		}
+
+		want := append(frames[:len(frames):len(frames)], frames...)
+		if len(got) != len(want) {
+			t.Fatalf("got %d frames, want %d", len(got), len(want))
+		}
+		for i := range want {
+			if !bytes.Equal(got[i], want[i]) {
+				t.Errorf("frame %d: got %v, want %v", i, got[i], want[i])
+			}
+		}
+	}
+}
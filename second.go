@@ -0,0 +1,105 @@
+package cobs
+
+import "errors"
+
+// ErrInvalidCode means that a frame contained a code that is not valid.
+var ErrInvalidCode = errors.New("invalid code")
+
+// With a second forbidden byte, groups end in a zero, in the second byte or
+// are full without ending. A code holds a value, which skips the forbidden
+// bytes. Values below secondMax are followed by that number of data bytes and
+// a zero, below 2*secondMax by the number minus secondMax and the second
+// byte. The value 2*secondMax is a full group of secondMax data bytes.
+const secondMax = 126
+
+// secondCode returns the code of value v, skipping the forbidden byte f.
+func secondCode(v int, f byte) byte {
+	c := byte(v + 1)
+	if c >= f {
+		c++
+	}
+
+	return c
+}
+
+// secondValue returns the value of code, or -1 if code is not valid.
+func secondValue(code, f byte) int {
+	switch {
+	case code == Delimiter || code == f:
+		return -1
+	case code > f:
+		code--
+	}
+
+	if v := int(code) - 1; v <= 2*secondMax {
+		return v
+	}
+
+	return -1
+}
+
+// secondData returns the number of data bytes following code, or -1 if code
+// is not valid.
+func secondData(code, f byte) int {
+	switch v := secondValue(code, f); {
+	case v < 0:
+		return -1
+	case v == 2*secondMax:
+		return secondMax
+	default:
+		return v % secondMax
+	}
+}
+
+// encodeSecond adds c to the current group, ending it on a zero and on the
+// second forbidden byte.
+func (e *Encoder) encodeSecond(c byte) error {
+	n := len(e.buf) - 1
+
+	// Finish if group is full
+	if n == secondMax {
+		e.buf[0] = secondCode(2*secondMax, e.forbid)
+		if err := e.finish(); err != nil {
+			return err
+		}
+		n = 0
+	}
+
+	switch c {
+	case e.split:
+		e.buf[0] = secondCode(n, e.forbid)
+
+		return e.finish()
+	case e.forbid:
+		e.buf[0] = secondCode(secondMax+n, e.forbid)
+
+		return e.finish()
+	}
+
+	e.buf = append(e.buf, c)
+
+	return nil
+}
+
+// closeSecond sets the code of the last group, which ends in the zero that
+// ends every frame unless it is full.
+func (e *Encoder) closeSecond() {
+	n := len(e.buf) - 1
+	if n == secondMax {
+		e.buf[0] = secondCode(2*secondMax, e.forbid)
+	} else {
+		e.buf[0] = secondCode(n, e.forbid)
+	}
+}
+
+// endSecond emits the byte ending the group of the previous code.
+func (d *Decoder) endSecond() error {
+	switch v := secondValue(d.code, d.forbid); {
+	case v < secondMax:
+		return d.emit(d.inserted)
+	case v < 2*secondMax:
+		return d.emit(d.forbid)
+	}
+
+	return nil
+}
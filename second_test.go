@@ -0,0 +1,115 @@
+package cobs
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestSecondForbidden(t *testing.T) {
+	testCases := []struct {
+		name     string
+		dec, enc []byte
+	}{
+		{
+			name: "Empty",
+			dec:  []byte{},
+			enc:  []byte{0x01},
+		},
+		{
+			name: "Zero",
+			dec:  []byte("a\x00b"),
+			enc:  []byte("\x02a\x02b"),
+		},
+		{
+			name: "Second byte",
+			dec:  []byte("a\x7eb"),
+			enc:  []byte("\x81a\x02b"),
+		},
+		{
+			name: "Only second byte",
+			dec:  []byte{0x7e},
+			enc:  []byte{0x80, 0x01},
+		},
+		{
+			name: "Full group",
+			dec:  bytes.Repeat([]byte{'a'}, 126),
+			enc:  append([]byte{0xfe}, bytes.Repeat([]byte{'a'}, 126)...),
+		},
+		{
+			name: "Full group and zero",
+			dec:  append(bytes.Repeat([]byte{'a'}, 126), 0x00),
+			enc:  append(append([]byte{0xfe}, bytes.Repeat([]byte{'a'}, 126)...), 0x01, 0x01),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			enc, err := Encode(tc.dec, WithSecondForbidden(0x7e))
+			if err != nil {
+				t.Fatalf("encode error: %v", err)
+			}
+			if !bytes.Equal(enc, tc.enc) {
+				t.Errorf("encode got %v, want %v", enc, tc.enc)
+			}
+
+			dec, err := Decode(tc.enc, WithSecondForbidden(0x7e))
+			if err != nil {
+				t.Fatalf("decode error: %v", err)
+			}
+			if !bytes.Equal(dec, tc.dec) {
+				t.Errorf("decode got %v, want %v", dec, tc.dec)
+			}
+
+			if err := Validate(tc.enc, WithSecondForbidden(0x7e)); err != nil {
+				t.Errorf("validate error: %v", err)
+			}
+		})
+	}
+}
+
+func TestSecondForbiddenErrors(t *testing.T) {
+	_, err := Decode([]byte("\x02a\x7e"), WithSecondForbidden(0x7e))
+	if !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("got %v, want %v", err, ErrInvalidCode)
+	}
+
+	_, err = Encode([]byte("a"), WithSecondForbidden(0x7e), WithZPE(true))
+	if !errors.Is(err, ErrIncompatibleOptions) {
+		t.Errorf("got %v, want %v", err, ErrIncompatibleOptions)
+	}
+
+	_, err = Encode([]byte("a"), WithSecondForbidden(0x42), WithSentinel(0x42))
+	if !errors.Is(err, ErrIncompatibleOptions) {
+		t.Errorf("got %v, want %v", err, ErrIncompatibleOptions)
+	}
+}
+
+func FuzzSecondForbidden(f *testing.F) {
+	f.Add([]byte("a\x00b\x7ec"), byte(0x00), byte(0x7e))
+	f.Add(bytes.Repeat([]byte("\x7e\x00\x42"), 100), byte(0x42), byte(0x7e))
+	f.Fuzz(func(t *testing.T, a []byte, sentinel, forbidden byte) {
+		if sentinel == forbidden {
+			return
+		}
+		opts := []Option{WithSentinel(sentinel), WithSecondForbidden(forbidden)}
+
+		enc, err := Encode(a, opts...)
+		if err != nil {
+			t.Fatalf("encode error: %v", err)
+		}
+		for i, c := range enc {
+			if c == sentinel || c == forbidden {
+				t.Errorf("encoded %v has forbidden byte at %d", enc, i)
+			}
+		}
+
+		dec, err := Decode(enc, opts...)
+		if err != nil {
+			t.Fatalf("decode error: %v", err)
+		}
+		if !bytes.Equal(dec, a) {
+			t.Errorf("got %v, want %v", dec, a)
+		}
+	})
+}
@@ -0,0 +1,46 @@
+package cobs
+
+// A SentinelMode selects how a sentinel other than 0x00 is kept out of the
+// encoded data.
+type SentinelMode int
+
+const (
+	SentinelXOR    SentinelMode = iota // encoded data is XORed with the sentinel
+	SentinelNative                     // the sentinel is stuffed instead of 0x00
+)
+
+// With SentinelNative groups end in the sentinel instead of a zero, so data
+// bytes other than the sentinel are written unchanged. A code equal to the
+// sentinel is written as 0x00, which no code uses otherwise.
+
+// swapSentinel exchanges the values 0x00 and s, and returns other values of c
+// unchanged.
+func swapSentinel(c, s byte) byte {
+	switch c {
+	case Delimiter:
+		return s
+	case s:
+		return Delimiter
+	}
+
+	return c
+}
+
+// unmask returns the byte c read from the encoded data as if the sentinel
+// were 0x00.
+func (c *Config) unmask(b byte) byte {
+	if c.SentinelMode == SentinelNative {
+		return swapSentinel(b, c.Sentinel)
+	}
+
+	return b ^ c.Sentinel
+}
+
+// unmask is like config.unmask for the Decoder.
+func (d *Decoder) unmask(c byte) byte {
+	if d.native {
+		return swapSentinel(c, d.sentinel)
+	}
+
+	return c ^ d.sentinel
+}
@@ -0,0 +1,112 @@
+package cobs
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+var nativeCases = []struct {
+	name     string
+	sentinel byte
+	dec, enc []byte
+}{
+	{
+		name:     "Sentinel stuffed",
+		sentinel: '\n',
+		dec:      []byte("a\nb\x00"),
+		enc:      []byte("\x02a\x03b\x00"),
+	},
+	{
+		name:     "Code equals sentinel",
+		sentinel: 0x03,
+		dec:      []byte("ab"),
+		enc:      []byte("\x00ab"),
+	},
+	{
+		name:     "Zero sentinel",
+		sentinel: 0x00,
+		dec:      []byte("a\x00b"),
+		enc:      []byte("\x02a\x02b"),
+	},
+}
+
+func TestSentinelNative(t *testing.T) {
+	for _, tc := range nativeCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := []Option{WithSentinel(tc.sentinel), WithSentinelMode(SentinelNative)}
+
+			enc, err := Encode(tc.dec, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(enc, tc.enc) {
+				t.Errorf("Encode: got %q, want %q", enc, tc.enc)
+			}
+
+			dec, err := Decode(tc.enc, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(dec, tc.dec) {
+				t.Errorf("Decode: got %q, want %q", dec, tc.dec)
+			}
+		})
+	}
+}
+
+func TestSentinelNativeRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	variants := []Option{WithReduced(false), WithReduced(true), WithZPE(true), WithZRE(true)}
+
+	for _, s := range []byte{0x01, 0x0a, 0x7e, 0xe1, 0xff} {
+		for _, v := range variants {
+			opts := []Option{WithSentinel(s), WithSentinelMode(SentinelNative), v}
+
+			for _, n := range []int{0, 1, 254, 255, 1000} {
+				data := make([]byte, n)
+				rng.Read(data)
+				for i := range data {
+					if rng.Intn(4) == 0 {
+						data[i] = s
+					}
+				}
+
+				enc, err := Encode(data, opts...)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if bytes.IndexByte(enc, s) != -1 {
+					t.Fatalf("sentinel %#x in encoded data", s)
+				}
+
+				dec, err := Decode(enc, opts...)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(dec, data) {
+					t.Errorf("sentinel %#x, %d bytes: round trip mismatch", s, n)
+				}
+			}
+		}
+	}
+}
+
+func TestSentinelNativeIncompatible(t *testing.T) {
+	_, err := Encode([]byte(nil), WithSentinelMode(SentinelNative), WithInsertedByte(1))
+	if !errors.Is(err, ErrIncompatibleOptions) {
+		t.Errorf("got %v, want ErrIncompatibleOptions", err)
+	}
+}
+
+func TestSentinelNativeValidate(t *testing.T) {
+	for _, tc := range nativeCases {
+		opts := []Option{WithSentinel(tc.sentinel), WithSentinelMode(SentinelNative)}
+
+		if err := Validate(tc.enc, opts...); err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package cobs
+
+// putSequence encodes the sequence number at the start of a frame.
+func (e *Encoder) putSequence() error {
+	if !e.seq || e.seqDone {
+		return nil
+	}
+	e.seqDone = true
+
+	err := e.put(e.seqNum)
+	e.seqNum++
+
+	return err
+}
+
+// endSequence checks the sequence number of a complete frame against the
+// previous one, counting lost and duplicate frames.
+func (d *Decoder) endSequence() error {
+	if !d.seqDone {
+		return d.frameError(ErrIncompleteFrame, d.offset)
+	}
+
+	if d.seqSeen {
+		switch diff := d.seqNum - d.lastSeq; diff {
+		case 0:
+			d.stats.Duplicates++
+		case 1:
+		default:
+			d.stats.Lost += uint64(diff - 1)
+		}
+	}
+	d.lastSeq = d.seqNum
+	d.seqSeen = true
+
+	return nil
+}
+
+// LastSequence returns the sequence number of the last complete frame with
+// WithSequence, ok is false if there was none.
+func (d *Decoder) LastSequence() (seq byte, ok bool) {
+	return d.lastSeq, d.seqSeen
+}
@@ -0,0 +1,72 @@
+package cobs
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestSequence(t *testing.T) {
+	var enc bytes.Buffer
+	e := mustEncoder(t, &enc, WithSequence(true), WithDelimiterOnClose(true))
+
+	var frames [][]byte
+	for i, payload := range []string{"a", "", "b\x00c", "d"} {
+		start := enc.Len()
+		if _, err := e.Write([]byte(payload)); err != nil {
+			t.Fatalf("write error: %v", err)
+		}
+		if err := e.Close(); err != nil {
+			t.Fatalf("close error: %v", err)
+		}
+		frames = append(frames, append([]byte(nil), enc.Bytes()[start:]...))
+
+		raw, _ := Decode(bytes.TrimSuffix(frames[i], []byte{Delimiter}))
+		if raw[0] != byte(i) {
+			t.Errorf("frame %d: got sequence %d", i, raw[0])
+		}
+	}
+
+	// Frame 1 is lost, frame 2 is repeated
+	var dec bytes.Buffer
+	d := mustDecoder(t, &dec, WithSequence(true), WithAutoReset(true), WithFrameSeparator([]byte("|")))
+	for _, i := range []int{0, 2, 2, 3} {
+		if _, err := d.Write(frames[i]); err != nil {
+			t.Fatalf("write error: %v", err)
+		}
+	}
+
+	if got, want := dec.String(), "a|b\x00c|b\x00c|d|"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if s := d.Stats(); s.Lost != 1 || s.Duplicates != 1 {
+		t.Errorf("got %d lost, %d duplicates, want 1 and 1", s.Lost, s.Duplicates)
+	}
+	if seq, ok := d.LastSequence(); seq != 3 || !ok {
+		t.Errorf("got sequence %d, %v, want 3, true", seq, ok)
+	}
+
+	// An empty frame has no sequence number
+	if _, err := d.Write([]byte{0x01, 0x00}); !errors.Is(err, ErrIncompleteFrame) {
+		t.Errorf("got %v, want %v", err, ErrIncompleteFrame)
+	}
+}
+
+func TestSequenceWrap(t *testing.T) {
+	enc, err := EncodeFrames(make([][]byte, 300), WithSequence(true))
+	if err != nil {
+		t.Fatalf("encode error: %v", err)
+	}
+
+	d := mustDecoder(t, nil, WithSequence(true), WithAutoReset(true))
+	if _, err := d.Write(enc); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+
+	if s := d.Stats(); s.Frames != 300 || s.Lost != 0 || s.Duplicates != 0 {
+		t.Errorf("got %+v, want 300 frames without loss", s)
+	}
+	if seq, _ := d.LastSequence(); seq != 299%256 {
+		t.Errorf("got sequence %d, want %d", seq, 299%256)
+	}
+}
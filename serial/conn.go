@@ -0,0 +1,100 @@
+package serial
+
+import (
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"go.bug.st/serial"
+)
+
+// An Addr is the address of a serial port, its device name.
+type Addr string
+
+// Network returns "serial".
+func (a Addr) Network() string {
+	return "serial"
+}
+
+func (a Addr) String() string {
+	return string(a)
+}
+
+// conn makes a serial.Port a net.Conn. A serial port only has a timeout for
+// each read, which is derived from the read deadline when a read starts.
+// Changing the deadline does not affect a blocked read. Writes have no
+// deadline, they end once the data is transmitted, so SetDeadline only sets
+// the read deadline and SetWriteDeadline reports os.ErrNoDeadline.
+type conn struct {
+	port serial.Port
+	addr Addr
+
+	mu       sync.Mutex
+	deadline time.Time
+}
+
+func newConn(port serial.Port, device string) *conn {
+	return &conn{port: port, addr: Addr(device)}
+}
+
+func (c *conn) Read(p []byte) (int, error) {
+	c.mu.Lock()
+	deadline := c.deadline
+	c.mu.Unlock()
+
+	timeout := serial.NoTimeout
+	if !deadline.IsZero() {
+		if timeout = time.Until(deadline); timeout <= 0 {
+			return 0, os.ErrDeadlineExceeded
+		}
+	}
+
+	if err := c.port.SetReadTimeout(timeout); err != nil {
+		return 0, err
+	}
+
+	// A read that times out returns no data and no error
+	n, err := c.port.Read(p)
+	if n == 0 && err == nil {
+		return 0, os.ErrDeadlineExceeded
+	}
+
+	return n, err
+}
+
+func (c *conn) Write(p []byte) (int, error) {
+	return c.port.Write(p)
+}
+
+func (c *conn) Close() error {
+	return c.port.Close()
+}
+
+func (c *conn) LocalAddr() net.Addr {
+	return c.addr
+}
+
+func (c *conn) RemoteAddr() net.Addr {
+	return c.addr
+}
+
+func (c *conn) SetDeadline(t time.Time) error {
+	return c.SetReadDeadline(t)
+}
+
+func (c *conn) SetReadDeadline(t time.Time) error {
+	c.mu.Lock()
+	c.deadline = t
+	c.mu.Unlock()
+
+	return nil
+}
+
+func (c *conn) SetWriteDeadline(t time.Time) error {
+	if t.IsZero() {
+		return nil
+	}
+
+	return os.ErrNoDeadline
+}
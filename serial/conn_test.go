@@ -0,0 +1,83 @@
+package serial
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/pdgendt/cobs"
+	"go.bug.st/serial"
+)
+
+// fakePort is a serial.Port reading from in and writing to out. A read
+// without data times out.
+type fakePort struct {
+	serial.Port
+	in, out bytes.Buffer
+	timeout time.Duration
+}
+
+func (p *fakePort) Read(b []byte) (int, error) {
+	if p.in.Len() == 0 && p.timeout == serial.NoTimeout {
+		return 0, errors.New("read blocks forever")
+	}
+
+	n, _ := p.in.Read(b)
+
+	return n, nil
+}
+
+func (p *fakePort) Write(b []byte) (int, error) {
+	return p.out.Write(b)
+}
+
+func (p *fakePort) SetReadTimeout(t time.Duration) error {
+	p.timeout = t
+
+	return nil
+}
+
+func (p *fakePort) Close() error {
+	return nil
+}
+
+func TestConn(t *testing.T) {
+	port := &fakePort{}
+	port.in.WriteString("\x02a\x00\x03b")
+
+	fc := cobs.NewFrameConn(newConn(port, "/dev/ttyUSB0"))
+
+	if err := fc.WriteFrame([]byte("c\x00")); err != nil {
+		t.Fatal(err)
+	}
+	if got := port.out.String(); got != "\x02c\x01\x00" {
+		t.Errorf("got %q, want %q", got, "\x02c\x01\x00")
+	}
+
+	msg, err := fc.ReadFrame()
+	if err != nil || string(msg) != "a" {
+		t.Fatalf("got %q, %v, want %q", msg, err, "a")
+	}
+
+	// A read timeout is reported as an expired deadline
+	if err := fc.SetReadDeadline(time.Now().Add(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fc.ReadFrame(); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("got %v, want %v", err, os.ErrDeadlineExceeded)
+	}
+
+	// Writes have no deadline
+	if err := fc.SetWriteDeadline(time.Now().Add(time.Second)); !errors.Is(err, os.ErrNoDeadline) {
+		t.Errorf("got %v, want %v", err, os.ErrNoDeadline)
+	}
+	if err := fc.SetWriteDeadline(time.Time{}); err != nil {
+		t.Errorf("clearing write deadline: %v", err)
+	}
+
+	if addr := fc.RemoteAddr(); addr.Network() != "serial" || addr.String() != "/dev/ttyUSB0" {
+		t.Errorf("got address %v", addr)
+	}
+}
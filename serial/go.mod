@@ -0,0 +1,13 @@
+module github.com/pdgendt/cobs/serial
+
+go 1.18
+
+require (
+	github.com/pdgendt/cobs v0.0.0
+	go.bug.st/serial v1.6.4
+)
+
+require (
+	github.com/creack/goselect v0.1.2 // indirect
+	golang.org/x/sys v0.19.0 // indirect
+)
@@ -0,0 +1,19 @@
+// Package serial opens serial ports, like the UART of a microcontroller, for
+// exchanging COBS frames.
+package serial
+
+import (
+	"github.com/pdgendt/cobs"
+	"go.bug.st/serial"
+)
+
+// Open opens the serial device with mode and returns a cobs.FrameConn
+// exchanging frames over it with the options.
+func Open(device string, mode *serial.Mode, opts ...cobs.Option) (*cobs.FrameConn, error) {
+	port, err := serial.Open(device, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	return cobs.NewFrameConn(newConn(port, device), opts...), nil
+}
@@ -0,0 +1,27 @@
+package cobs
+
+// Stats holds the cumulative counters of an Encoder or Decoder since it was
+// created or reset.
+type Stats struct {
+	BytesIn  uint64 // bytes consumed from Write and WriteByte
+	BytesOut uint64 // bytes written to the io.Writer or passed to a frame handler
+	Frames   uint64 // complete frames
+	Errors   uint64 // failed writes and, for a Decoder, frame errors
+
+	// With WithSequence, frames a Decoder found missing or repeated
+	Lost       uint64
+	Duplicates uint64
+}
+
+// Stats returns the counters of e.
+func (e *Encoder) Stats() Stats {
+	return e.stats
+}
+
+// Stats returns the counters of d. Frames equals FramesDecoded.
+func (d *Decoder) Stats() Stats {
+	s := d.stats
+	s.Frames = d.frames
+
+	return s
+}
@@ -0,0 +1,72 @@
+package cobs
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestEncoderStats(t *testing.T) {
+	var buf bytes.Buffer
+	e := mustEncoder(t, &buf, WithDelimiterOnClose(true))
+
+	for _, frame := range []string{"Hello\x00world", "abc"} {
+		if _, err := e.Write([]byte(frame)); err != nil {
+			t.Fatalf("write error: %v", err)
+		}
+		if err := e.Close(); err != nil {
+			t.Fatalf("close error: %v", err)
+		}
+	}
+
+	want := Stats{BytesIn: 14, BytesOut: uint64(buf.Len()), Frames: 2}
+	if got := e.Stats(); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	e = mustEncoder(t, &limitWriter{n: 3})
+	if _, err := e.Write([]byte("Hello\x00")); !errors.Is(err, errLimit) {
+		t.Fatalf("got %v, want %v", err, errLimit)
+	}
+
+	want = Stats{BytesIn: 6, BytesOut: 3, Errors: 1}
+	if got := e.Stats(); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	e.Reset(&buf)
+	if got := e.Stats(); got != (Stats{}) {
+		t.Errorf("got %+v after reset, want zero", got)
+	}
+}
+
+func TestDecoderStats(t *testing.T) {
+	var buf bytes.Buffer
+	d := mustDecoder(t, &buf)
+
+	enc, _ := EncodeFrames([][]byte{[]byte("Hello\x00world"), []byte("abc")})
+	for p := enc; len(p) > 0; {
+		n, err := d.Write(p)
+		if err != EOD {
+			t.Fatalf("got %v, want %v", err, EOD)
+		}
+		p = p[n+1:]
+	}
+
+	want := Stats{BytesIn: uint64(len(enc)), BytesOut: 14, Frames: 2}
+	if got := d.Stats(); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	// A malformed frame counts as error, its data was already written
+	if _, err := d.Write([]byte("\x03a\x00")); !errors.Is(err, ErrUnexpectedEOD) {
+		t.Fatalf("got %v, want %v", err, ErrUnexpectedEOD)
+	}
+
+	want.BytesIn += 3
+	want.BytesOut++
+	want.Errors++
+	if got := d.Stats(); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
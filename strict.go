@@ -0,0 +1,70 @@
+package cobs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// ErrNonCanonical means that a frame was not encoded the way a conforming
+// encoder does, although it decodes without error.
+var ErrNonCanonical = errors.New("non-canonical encoding")
+
+// A GroupError records a group that ends early, its code declares more data
+// bytes than precede the delimiter. It wraps ErrUnexpectedEOD.
+type GroupError struct {
+	Code byte
+	Want int
+	Got  int
+}
+
+func (e *GroupError) Error() string {
+	return fmt.Sprintf("%v: code 0x%02x declares %d bytes, got %d",
+		ErrUnexpectedEOD, e.Code, e.Want, e.Got)
+}
+
+func (e *GroupError) Unwrap() error {
+	return ErrUnexpectedEOD
+}
+
+// checkGroup looks ahead in p, which starts with a code byte, for a delimiter
+// ending the group early.
+func (d *Decoder) checkGroup(p []byte) error {
+	c := d.unmask(p[0])
+	if c == Delimiter || d.discard || d.delimRest > 0 {
+		return nil
+	}
+
+	n := d.groupLen(c)
+	if n < 0 {
+		return nil
+	}
+
+	group := p[1:]
+	if len(group) > n {
+		group = group[:n]
+	}
+
+	if j := bytes.IndexByte(group, d.sentinel); j != -1 {
+		return d.frameError(&GroupError{Code: c, Want: n, Got: j}, d.offset)
+	}
+
+	return nil
+}
+
+// checkCanonical checks that the group of the current code, which has ended,
+// is encoded canonically. An empty group following a full group at the end of
+// a frame adds nothing, with COBS/ZPE an empty group following a short group
+// belongs in a zero pair.
+func (d *Decoder) checkCanonical(end bool) error {
+	if !d.strict || d.second || d.code != 1 {
+		return nil
+	}
+
+	if (end && d.groups > 1 && d.prev == d.full) ||
+		(d.zpe && d.prev >= 1 && d.prev <= zpeMaxPair+1) {
+		return d.frameError(ErrNonCanonical, d.codeAt)
+	}
+
+	return nil
+}
@@ -0,0 +1,164 @@
+package cobs
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestStrict(t *testing.T) {
+	type strictCase struct {
+		name   string
+		enc    []byte
+		offset int
+		group  GroupError
+	}
+
+	testCases := []strictCase{
+		{
+			name:   "Inflated first code",
+			enc:    []byte("\x05ab\x00\x02c"),
+			offset: 0,
+			group:  GroupError{Code: 0x05, Want: 4, Got: 2},
+		},
+		{
+			name:   "Inflated last code",
+			enc:    []byte("\x02a\x02b\xf0cd\x00"),
+			offset: 4,
+			group:  GroupError{Code: 0xf0, Want: 239, Got: 2},
+		},
+	}
+
+	// The same frames as without strict mode, reported at the code
+	groups := []GroupError{
+		{Code: 0x03, Want: 2, Got: 1},
+		{Code: 0x04, Want: 3, Got: 2},
+		{Code: 0xff, Want: 254, Got: 0},
+	}
+	offsets := []int{0, 2, 0}
+	for i, tc := range unexpectedDelimiter {
+		testCases = append(testCases, strictCase{
+			name:   tc.name,
+			enc:    tc.enc,
+			offset: offsets[i],
+			group:  groups[i],
+		})
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Both looking ahead and byte by byte
+			for _, write := range []func(*Decoder, []byte) (int, error){(*Decoder).Write, writeBytes} {
+				var buf bytes.Buffer
+				d := mustDecoder(t, &buf, WithStrict(true))
+
+				_, err := write(d, tc.enc)
+				if !errors.Is(err, ErrUnexpectedEOD) {
+					t.Fatalf("got %v, want %v", err, ErrUnexpectedEOD)
+				}
+
+				var fe *FrameError
+				var ge *GroupError
+				if !errors.As(err, &fe) || !errors.As(err, &ge) {
+					t.Fatalf("got %T, want *FrameError wrapping *GroupError", err)
+				}
+				if fe.Offset != tc.offset {
+					t.Errorf("offset got %d, want %d", fe.Offset, tc.offset)
+				}
+				if *ge != tc.group {
+					t.Errorf("got %+v, want %+v", *ge, tc.group)
+				}
+			}
+		})
+	}
+}
+
+func TestStrictWithholdsGroup(t *testing.T) {
+	var buf bytes.Buffer
+	d := mustDecoder(t, &buf, WithStrict(true))
+
+	n, err := d.Write([]byte("\x03ab\x05cd\x00"))
+	if !errors.Is(err, ErrUnexpectedEOD) {
+		t.Fatalf("got %v, want %v", err, ErrUnexpectedEOD)
+	}
+	if n != 3 {
+		t.Errorf("consumed %d, want 3", n)
+	}
+	if buf.String() != "ab" {
+		t.Errorf("got %q, want %q", buf.String(), "ab")
+	}
+}
+
+func TestStrictNonCanonical(t *testing.T) {
+	full := append([]byte{0xff}, bytes.Repeat([]byte{'a'}, 254)...)
+
+	testCases := []struct {
+		name   string
+		enc    []byte
+		opts   []Option
+		offset int
+	}{
+		{
+			name:   "Empty group after full group",
+			enc:    append(full[:len(full):len(full)], 0x01, 0x00),
+			offset: 255,
+		},
+		{
+			name:   "Zeros not paired",
+			enc:    []byte("\x02a\x01\x02b\x00"),
+			opts:   []Option{WithZPE(true)},
+			offset: 2,
+		},
+		{
+			name:   "Zero not paired with frame end",
+			enc:    []byte("\x02a\x01\x00"),
+			opts:   []Option{WithZPE(true)},
+			offset: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Accepted without strict mode
+			if _, err := mustDecoder(t, &bytes.Buffer{}, tc.opts...).Write(tc.enc); err != EOD {
+				t.Fatalf("got %v, want %v", err, EOD)
+			}
+
+			d := mustDecoder(t, &bytes.Buffer{}, append(tc.opts, WithStrict(true))...)
+			_, err := d.Write(tc.enc)
+
+			var fe *FrameError
+			if !errors.As(err, &fe) || !errors.Is(err, ErrNonCanonical) {
+				t.Fatalf("got %v, want %v", err, ErrNonCanonical)
+			}
+			if fe.Offset != tc.offset {
+				t.Errorf("got offset %d, want %d", fe.Offset, tc.offset)
+			}
+		})
+	}
+}
+
+func TestStrictCanonical(t *testing.T) {
+	payloads := [][]byte{
+		nil,
+		{0x00},
+		{0x00, 0x00, 0x00},
+		bytes.Repeat([]byte{'a'}, 254),
+		append(bytes.Repeat([]byte{'a'}, 254), 0x00),
+		[]byte("a\x00\x00b\x00c\x00\x00\x00"),
+	}
+
+	for _, opts := range [][]Option{nil, {WithZPE(true)}} {
+		for _, p := range payloads {
+			enc, err := Encode(p, append(opts, WithDelimiterOnClose(true))...)
+			if err != nil {
+				t.Fatalf("encode error: %v", err)
+			}
+
+			d := mustDecoder(t, &bytes.Buffer{}, append(opts, WithStrict(true))...)
+			if _, err := d.Write(enc); err != EOD {
+				t.Errorf("%q: got %v, want %v", p, err, EOD)
+			}
+		}
+	}
+}
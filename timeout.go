@@ -0,0 +1,39 @@
+package cobs
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrFrameTimeout means that a frame was not completed within the time set
+// by WithFrameTimeout.
+var ErrFrameTimeout = errors.New("frame timeout")
+
+// checkTimeout drops the frame in progress if it is older than the frame
+// timeout and returns a FrameError wrapping ErrFrameTimeout. Input is then
+// discarded up to the next delimiter. A frame starts its timeout with its
+// first byte, see decode.
+func (d *Decoder) checkTimeout() error {
+	if d.offset == 0 || d.clock.Now().Sub(d.began) < d.timeout {
+		return nil
+	}
+
+	err := d.frameError(ErrFrameTimeout, d.offset)
+	d.reset()
+	d.discard = true
+	if d.resync && d.metrics != nil {
+		d.metrics.Resync()
+	}
+
+	return err
+}
+
+// frameDeadline returns the time the frame in progress times out, or the
+// zero time if there is none.
+func (d *Decoder) frameDeadline() time.Time {
+	if d.timeout <= 0 || d.offset == 0 {
+		return time.Time{}
+	}
+
+	return d.began.Add(d.timeout)
+}
@@ -0,0 +1,168 @@
+package cobs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestFrameTimeout(t *testing.T) {
+	for _, resync := range []bool{false, true} {
+		clk := &fakeClock{now: time.Unix(0, 0)}
+
+		var frames []string
+		handler := func(frame []byte) error {
+			frames = append(frames, string(frame))
+
+			return nil
+		}
+		d := mustDecoder(t, nil, WithFrameHandler(handler), WithFrameTimeout(time.Second),
+			WithResync(resync), withClock(clk))
+
+		if _, err := d.Write([]byte("\x03a")); err != nil {
+			t.Fatal(err)
+		}
+
+		// The device stalls in the middle of a frame
+		clk.now = clk.now.Add(2 * time.Second)
+
+		_, err := d.Write([]byte("b\x00\x02c\x00"))
+		if resync {
+			if err != nil {
+				t.Fatalf("resync: got %v", err)
+			}
+		} else {
+			var fe *FrameError
+			if !errors.Is(err, ErrFrameTimeout) || !errors.As(err, &fe) || fe.Offset != 2 {
+				t.Fatalf("got %v, want %v at offset 2", err, ErrFrameTimeout)
+			}
+
+			// The rest of the dropped frame is discarded
+			if _, err := d.Write([]byte("b\x00\x02c\x00")); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if len(frames) != 1 || frames[0] != "c" {
+			t.Errorf("resync %v: got frames %q, want %q", resync, frames, []string{"c"})
+		}
+		if got := d.Stats().Errors; got != 1 {
+			t.Errorf("resync %v: got %d errors, want 1", resync, got)
+		}
+	}
+}
+
+func TestReaderFrameTimeout(t *testing.T) {
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	defer c2.Close()
+
+	go func() {
+		_, _ = c2.Write([]byte("\x02a\x00\x03b"))
+	}()
+
+	r := NewReader(c1, WithFrameTimeout(50*time.Millisecond))
+	buf := make([]byte, 16)
+
+	// The stalled frame ends the Reader once the data before is read
+	var got []byte
+	var err error
+	for err == nil {
+		var n int
+		n, err = r.Read(buf)
+		got = append(got, buf[:n]...)
+	}
+
+	if !errors.Is(err, ErrFrameTimeout) {
+		t.Errorf("got %v, want %v", err, ErrFrameTimeout)
+	}
+	if string(got) != "ab" {
+		t.Errorf("got %q, want %q", got, "ab")
+	}
+}
+
+func TestFrameTimeoutStartsWithFrame(t *testing.T) {
+	clk := &fakeClock{now: time.Unix(0, 0)}
+
+	var frames []string
+	handler := func(frame []byte) error {
+		frames = append(frames, string(frame))
+
+		return nil
+	}
+	d := mustDecoder(t, nil, WithFrameHandler(handler), WithFrameTimeout(time.Second), withClock(clk))
+
+	if _, err := d.Write([]byte("\x03a")); err != nil {
+		t.Fatal(err)
+	}
+
+	// The next frame starts in the same write that ends the first one
+	clk.now = clk.now.Add(900 * time.Millisecond)
+	if _, err := d.Write([]byte("b\x00\x03c")); err != nil {
+		t.Fatal(err)
+	}
+
+	clk.now = clk.now.Add(600 * time.Millisecond)
+	if _, err := d.Write([]byte("d\x00")); err != nil {
+		t.Fatalf("got %v, want no timeout", err)
+	}
+
+	if len(frames) != 2 || frames[0] != "ab" || frames[1] != "cd" {
+		t.Errorf("got frames %q, want %q", frames, []string{"ab", "cd"})
+	}
+}
+
+// deadlineReader records the read deadlines set on it.
+type deadlineReader struct {
+	reads     [][]byte
+	deadlines []time.Time
+}
+
+func (r *deadlineReader) Read(p []byte) (int, error) {
+	if len(r.reads) == 0 {
+		return 0, io.EOF
+	}
+
+	n := copy(p, r.reads[0])
+	r.reads = r.reads[1:]
+
+	return n, nil
+}
+
+func (r *deadlineReader) SetReadDeadline(t time.Time) error {
+	r.deadlines = append(r.deadlines, t)
+
+	return nil
+}
+
+func TestReaderKeepsDeadline(t *testing.T) {
+	src := &deadlineReader{reads: [][]byte{[]byte("\x02a\x00\x03b"), []byte("c\x00")}}
+	r := NewReader(src, WithFrameTimeout(time.Minute))
+
+	deadline := time.Now().Add(time.Hour)
+	if err := r.SetReadDeadline(deadline); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "abc" {
+		t.Errorf("got %q, want %q", got, "abc")
+	}
+
+	// Only the read in the middle of a frame is shortened
+	ds := src.deadlines
+	if len(ds) != 3 || !ds[0].Equal(deadline) || !ds[1].Before(deadline) || ds[1].IsZero() || !ds[2].Equal(deadline) {
+		t.Errorf("got deadlines %v, want %v, a frame deadline and %v", ds, deadline, deadline)
+	}
+
+	if err := NewReader(bytes.NewReader(nil)).SetReadDeadline(deadline); !errors.Is(err, os.ErrNoDeadline) {
+		t.Errorf("got %v, want %v", err, os.ErrNoDeadline)
+	}
+}
@@ -0,0 +1,106 @@
+package cobs
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"sync"
+)
+
+// errFrameDone stops a Decoder after a frame was received.
+var errFrameDone = errors.New("frame done")
+
+// A Transport exchanges messages as delimiter terminated frames over an
+// io.ReadWriteCloser, such as a serial port or a network connection.
+type Transport struct {
+	rwc   io.ReadWriteCloser
+	r     *bufio.Reader
+	d     *Decoder
+	frame []byte
+	opts  []Option
+	wmu   sync.Mutex
+}
+
+// NewTransport returns a Transport that sends and receives messages over rwc.
+// The options apply to both directions. Delimiters that do not end a frame
+// are skipped.
+func NewTransport(rwc io.ReadWriteCloser, opts ...Option) *Transport {
+	t := &Transport{
+		rwc:  rwc,
+		r:    bufio.NewReader(rwc),
+		opts: append(opts[:len(opts):len(opts)], WithDelimiterOnClose(true), WithRateLimit(0)),
+	}
+
+	t.d = newDecoder(nil, append(opts[:len(opts):len(opts)],
+		WithSkipEmptyFrames(true), WithFrameHandler(t.handle))...)
+
+	return t
+}
+
+func (t *Transport) handle(frame []byte) error {
+	t.frame = append([]byte(nil), frame...)
+
+	return errFrameDone
+}
+
+// Send encodes p and writes it as a single frame. It is safe to call Send
+// concurrently with Recv and with other calls of Send.
+func (t *Transport) Send(p []byte) error {
+	enc, err := Encode(p, t.opts...)
+	if err != nil {
+		return err
+	}
+
+	t.wmu.Lock()
+	defer t.wmu.Unlock()
+
+	_, err = writeFull(t.rwc, enc)
+
+	return err
+}
+
+// Recv reads and returns the next message. A malformed frame results in a
+// FrameError, the data up to the next delimiter is dropped so a following
+// call of Recv receives the next frame. If the input ends in the middle of a
+// frame io.ErrUnexpectedEOF is returned, otherwise io.EOF.
+func (t *Transport) Recv() ([]byte, error) {
+	for {
+		p, err := t.r.Peek(1)
+		if err == io.EOF && t.d.offset > 0 {
+			t.d.reset()
+			return nil, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		p, _ = t.r.Peek(t.r.Buffered())
+
+		// Only the bytes consumed by the decoder are dropped, which include
+		// the delimiter that ended a frame or the byte that failed to decode
+		in := t.d.stats.BytesIn
+		_, err = t.d.Write(p)
+		n := int(t.d.stats.BytesIn - in)
+		_, _ = t.r.Discard(n)
+
+		if err == nil {
+			continue
+		}
+		if err == errFrameDone {
+			return t.frame, nil
+		}
+
+		// Resynchronize on the next delimiter
+		if n > 0 && p[n-1] != t.d.sentinel {
+			t.d.discard = true
+		}
+		t.d.reset()
+
+		return nil, err
+	}
+}
+
+// Close closes the underlying io.ReadWriteCloser.
+func (t *Transport) Close() error {
+	return t.rwc.Close()
+}
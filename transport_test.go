@@ -0,0 +1,167 @@
+package cobs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTransport(t *testing.T) {
+	c1, c2 := net.Pipe()
+	a := NewTransport(c1)
+	b := NewTransport(c2)
+	defer a.Close()
+	defer b.Close()
+
+	msgs := [][]byte{
+		[]byte("hello"),
+		{},
+		[]byte("zero\x00byte"),
+		bytes.Repeat([]byte("long message\x00"), 100),
+	}
+
+	// b echoes every message back to a
+	done := make(chan error, 1)
+	go func() {
+		for range msgs {
+			msg, err := b.Recv()
+			if err != nil {
+				done <- err
+				return
+			}
+			if err := b.Send(msg); err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+
+	for _, msg := range msgs {
+		if err := a.Send(msg); err != nil {
+			t.Fatalf("send error: %v", err)
+		}
+
+		got, err := a.Recv()
+		if err != nil {
+			t.Fatalf("recv error: %v", err)
+		}
+		if !bytes.Equal(got, msg) {
+			t.Errorf("got %q, want %q", got, msg)
+		}
+	}
+
+	if err := <-done; err != nil {
+		t.Fatalf("echo error: %v", err)
+	}
+}
+
+// rwc is an in-memory io.ReadWriteCloser.
+type rwc struct {
+	io.Reader
+	io.Writer
+}
+
+func (rwc) Close() error {
+	return nil
+}
+
+func TestTransportResync(t *testing.T) {
+	input := []byte("\x00\x02a\x00" + // valid after idle delimiter
+		"\x05ab\x00" + // unexpected delimiter
+		"\x06abcde\x00" + // too large
+		"\x03bc\x00" + // valid
+		"\x04d") // truncated
+
+	tr := NewTransport(rwc{bytes.NewReader(input), io.Discard}, WithMaxFrameSize(3))
+
+	want := []struct {
+		msg string
+		err error
+	}{
+		{msg: "a"},
+		{err: ErrUnexpectedEOD},
+		{err: ErrFrameTooLarge},
+		{msg: "bc"},
+		{err: io.ErrUnexpectedEOF},
+		{err: io.EOF},
+	}
+
+	for i, w := range want {
+		msg, err := tr.Recv()
+		if !errors.Is(err, w.err) {
+			t.Fatalf("recv %d: got %v, want %v", i, err, w.err)
+		}
+		if string(msg) != w.msg {
+			t.Errorf("recv %d: got %q, want %q", i, msg, w.msg)
+		}
+	}
+}
+
+// chunkReader returns a chunk per read and calls next before each but the
+// first.
+type chunkReader struct {
+	chunks []string
+	next   func()
+	reads  int
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	if len(r.chunks) == 0 {
+		return 0, io.EOF
+	}
+	if r.reads > 0 {
+		r.next()
+	}
+	r.reads++
+
+	n := copy(p, r.chunks[0])
+	r.chunks = r.chunks[1:]
+
+	return n, nil
+}
+
+func TestTransportTimeoutKeepsDelimiter(t *testing.T) {
+	clk := &fakeClock{now: time.Unix(0, 0)}
+	r := &chunkReader{
+		chunks: []string{"\x03a", "\x00\x02b\x00"},
+		next:   func() { clk.Sleep(time.Second) },
+	}
+
+	tr := NewTransport(rwc{r, io.Discard}, WithFrameTimeout(time.Millisecond), withClock(clk))
+
+	// The delimiter after the timed out frame is not dropped
+	if _, err := tr.Recv(); !errors.Is(err, ErrFrameTimeout) {
+		t.Fatalf("got %v, want %v", err, ErrFrameTimeout)
+	}
+
+	msg, err := tr.Recv()
+	if err != nil || string(msg) != "b" {
+		t.Errorf("got %q, %v, want %q", msg, err, "b")
+	}
+}
+
+func TestTransportKeepAlive(t *testing.T) {
+	keepAlives := 0
+	opt := WithKeepAlive(func() error {
+		keepAlives++
+
+		return nil
+	})
+
+	tr := NewTransport(rwc{strings.NewReader("\x00\x02a\x00\x00\x01\x00"), io.Discard}, opt)
+
+	for _, want := range []string{"a", ""} {
+		msg, err := tr.Recv()
+		if err != nil || string(msg) != want {
+			t.Errorf("got %q, %v, want %q", msg, err, want)
+		}
+	}
+	if keepAlives != 2 {
+		t.Errorf("got %d keep-alives, want 2", keepAlives)
+	}
+}
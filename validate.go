@@ -0,0 +1,71 @@
+package cobs
+
+import "bytes"
+
+// Validate checks that data is a single complete frame, optionally
+// terminated by a delimiter, without decoding it. A FrameError wrapping
+// ErrUnexpectedEOD is returned for a delimiter inside the frame and one
+// wrapping ErrIncompleteFrame for a frame that ends in the middle of a group.
+// Only the framing is checked, a checksum is not verified. Validate does not
+// allocate, unless the frame is invalid.
+func Validate(data []byte, opts ...Option) error {
+	c := newConfig(opts)
+	if err := c.check(); err != nil {
+		return err
+	}
+
+	if len(data) == 0 || data[0] == c.Sentinel {
+		return validateError(ErrIncompleteFrame, 0)
+	}
+
+	for i := 0; i < len(data); {
+		if data[i] == c.Sentinel {
+			if i != len(data)-1 {
+				return validateError(ErrUnexpectedEOD, i)
+			}
+
+			return nil
+		}
+
+		// Check the data bytes of the group
+		n := groupData(c.unmask(data[i]), c.ZPE)
+		if c.MaxGroupSize > 0 && c.unmask(data[i]) > c.fullCode() {
+			return validateError(ErrInvalidCode, i)
+		}
+		if c.ZRE {
+			n = zreData(c.unmask(data[i]))
+		}
+		if c.SecondForbidden {
+			if n = secondData(data[i]^c.Sentinel, c.Forbidden^c.Sentinel); n < 0 {
+				return validateError(ErrInvalidCode, i)
+			}
+		}
+		end := i + 1 + n
+		group := data[i+1:]
+		if end < len(data) {
+			group = data[i+1 : end]
+		}
+
+		if j := bytes.IndexByte(group, c.Sentinel); j != -1 {
+			// A delimiter ends a reduced last group
+			if c.Reduced && i+1+j == len(data)-1 {
+				return nil
+			}
+
+			return validateError(ErrUnexpectedEOD, i+1+j)
+		}
+
+		if end > len(data) && !c.Reduced {
+			return validateError(ErrIncompleteFrame, len(data))
+		}
+
+		i = end
+	}
+
+	return nil
+}
+
+// validateError returns a FrameError for err at offset in data.
+func validateError(err error, offset int) error {
+	return &FrameError{Err: err, Offset: offset, InputOffset: int64(offset)}
+}
@@ -0,0 +1,134 @@
+package cobs
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := Validate(tc.enc); err != nil {
+				t.Errorf("got %v, want nil", err)
+			}
+			if err := Validate(append(tc.enc[:len(tc.enc):len(tc.enc)], Delimiter)); err != nil {
+				t.Errorf("delimited got %v, want nil", err)
+			}
+		})
+	}
+
+	for _, tc := range reducedCases {
+		t.Run("Reduced "+tc.name, func(t *testing.T) {
+			if err := Validate(tc.enc, WithReduced(true)); err != nil {
+				t.Errorf("got %v, want nil", err)
+			}
+		})
+	}
+
+	for _, tc := range unexpectedDelimiter {
+		t.Run(tc.name, func(t *testing.T) {
+			var fe *FrameError
+			err := Validate(tc.enc)
+			if !errors.Is(err, ErrUnexpectedEOD) || !errors.As(err, &fe) {
+				t.Fatalf("got %v, want %v", err, ErrUnexpectedEOD)
+			}
+			if fe.Offset != tc.offset {
+				t.Errorf("offset got %d, want %d", fe.Offset, tc.offset)
+			}
+		})
+	}
+
+	for _, tc := range incompleteFrame {
+		t.Run(tc.name, func(t *testing.T) {
+			var fe *FrameError
+			err := Validate(tc.enc)
+			if !errors.Is(err, ErrIncompleteFrame) || !errors.As(err, &fe) {
+				t.Fatalf("got %v, want %v", err, ErrIncompleteFrame)
+			}
+			if fe.Offset != tc.offset {
+				t.Errorf("offset got %d, want %d", fe.Offset, tc.offset)
+			}
+		})
+	}
+}
+
+func TestValidateFrame(t *testing.T) {
+	testCases := []struct {
+		name string
+		enc  []byte
+		opts []Option
+		err  error
+	}{
+		{
+			name: "Empty",
+			enc:  []byte{},
+			err:  ErrIncompleteFrame,
+		},
+		{
+			name: "Delimiter only",
+			enc:  []byte{0x00},
+			err:  ErrIncompleteFrame,
+		},
+		{
+			name: "Second frame",
+			enc:  []byte("\x02a\x00\x02b"),
+			err:  ErrUnexpectedEOD,
+		},
+		{
+			name: "Sentinel",
+			enc:  []byte("\x41\x23\x20\x42"),
+			opts: []Option{WithSentinel(0x42)},
+		},
+		{
+			name: "Zero with sentinel",
+			enc:  []byte("\x43\x20\x00"),
+			opts: []Option{WithSentinel(0x42)},
+			err:  ErrIncompleteFrame,
+		},
+		{
+			name: "Reduced with delimiter",
+			enc:  []byte("51234\x00"),
+			opts: []Option{WithReduced(true)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Validate(tc.enc, tc.opts...)
+			if !errors.Is(err, tc.err) {
+				t.Errorf("got %v, want %v", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestValidateAllocs(t *testing.T) {
+	enc := []byte("\x0612345\x056789\x01\x00")
+
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := Validate(enc); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("got %v allocs, want 0", allocs)
+	}
+}
+
+func BenchmarkValidate(b *testing.B) {
+	enc, err := Encode(bytes.Repeat([]byte("0123456789abcdef\x00"), 4096))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.SetBytes(int64(len(enc)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := Validate(enc); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package cobs
+
+// COBS/ZPE uses the codes up to zpeMaxCode like COBS, with zpeMaxCode for a
+// group that is not followed by a zero. From zpePairCode onwards a code is
+// followed by up to zpeMaxPair data bytes and two zeros.
+const (
+	zpeMaxCode  = 0xe0
+	zpePairCode = 0xe1
+	zpeMaxPair  = 0xff - zpePairCode
+)
+
+// maxCode returns the code of a group of maximum length, which is not
+// followed by a zero.
+func maxCode(zpe bool) byte {
+	if zpe {
+		return zpeMaxCode
+	}
+
+	return 0xff
+}
+
+// groupData returns the number of data bytes following code.
+func groupData(code byte, zpe bool) int {
+	if zpe && code >= zpePairCode {
+		return int(code - zpePairCode)
+	}
+
+	return int(code) - 1
+}
+
+// groupZeros returns the number of zeros following the group of code, if
+// another group follows.
+func groupZeros(code byte, zpe bool) int {
+	switch {
+	case code == maxCode(zpe):
+		return 0
+	case zpe && code >= zpePairCode:
+		return 2
+	default:
+		return 1
+	}
+}
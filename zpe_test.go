@@ -0,0 +1,122 @@
+package cobs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+var zpeCases = []struct {
+	name     string
+	dec, enc []byte
+}{
+	{
+		name: "Empty",
+		dec:  []byte{},
+		enc:  []byte{0x01},
+	},
+	{
+		name: "1 zero",
+		dec:  []byte{0x00},
+		enc:  []byte{0xe1},
+	},
+	{
+		name: "2 zeroes",
+		dec:  []byte{0x00, 0x00},
+		enc:  []byte{0xe1, 0x01},
+	},
+	{
+		name: "4 zeroes",
+		dec:  []byte{0x00, 0x00, 0x00, 0x00},
+		enc:  []byte{0xe1, 0xe1, 0x01},
+	},
+	{
+		name: "Single zero",
+		dec:  []byte("ab\x00cd"),
+		enc:  []byte("\x03ab\x03cd"),
+	},
+	{
+		name: "Zero pair",
+		dec:  []byte("ab\x00\x00cd"),
+		enc:  []byte("\xe3ab\x03cd"),
+	},
+	{
+		name: "Trailing zeroes",
+		dec:  []byte("a\x00\x00\x00"),
+		enc:  []byte("\xe2a\xe1"),
+	},
+	{
+		name: "Long group before zero pair",
+		dec:  append(bytes.Repeat([]byte{'a'}, 31), 0x00, 0x00),
+		enc:  append(append([]byte{0x20}, bytes.Repeat([]byte{'a'}, 31)...), 0xe1),
+	},
+	{
+		name: "Full group",
+		dec:  bytes.Repeat([]byte{'a'}, 224),
+		enc:  append(append([]byte{0xe0}, bytes.Repeat([]byte{'a'}, 223)...), 0x02, 'a'),
+	},
+}
+
+func TestZPE(t *testing.T) {
+	for _, tc := range zpeCases {
+		t.Run(tc.name, func(t *testing.T) {
+			enc, err := Encode(tc.dec, WithZPE(true))
+			if err != nil {
+				t.Fatalf("encode error: %v", err)
+			}
+			if !bytes.Equal(enc, tc.enc) {
+				t.Errorf("encode got %v, want %v", enc, tc.enc)
+			}
+
+			dec, err := Decode(tc.enc, WithZPE(true))
+			if err != nil {
+				t.Fatalf("decode error: %v", err)
+			}
+			if !bytes.Equal(dec, tc.dec) {
+				t.Errorf("decode got %v, want %v", dec, tc.dec)
+			}
+
+			if err := Validate(tc.enc, WithZPE(true)); err != nil {
+				t.Errorf("validate error: %v", err)
+			}
+		})
+	}
+}
+
+func TestZPEIncompatible(t *testing.T) {
+	opts := []Option{WithZPE(true), WithReduced(true)}
+
+	if _, err := Encode([]byte("a"), opts...); !errors.Is(err, ErrIncompatibleOptions) {
+		t.Errorf("encode got %v, want %v", err, ErrIncompatibleOptions)
+	}
+	if _, err := Decode([]byte("\x02a"), opts...); !errors.Is(err, ErrIncompatibleOptions) {
+		t.Errorf("decode got %v, want %v", err, ErrIncompatibleOptions)
+	}
+	if d, err := NewDecoder(io.Discard, opts...); d != nil || !errors.Is(err, ErrIncompatibleOptions) {
+		t.Errorf("new decoder got %v, want %v", err, ErrIncompatibleOptions)
+	}
+}
+
+func FuzzZPE(f *testing.F) {
+	for _, tc := range zpeCases {
+		f.Add(tc.dec)
+	}
+	f.Fuzz(func(t *testing.T, a []byte) {
+		enc, err := Encode(a, WithZPE(true))
+		if err != nil {
+			t.Fatalf("encode error: %v", err)
+		}
+		if i := bytes.IndexByte(enc, Delimiter); i != -1 {
+			t.Errorf("encoded %v has delimiter at %d", enc, i)
+		}
+
+		dec, err := Decode(enc, WithZPE(true))
+		if err != nil {
+			t.Fatalf("decode error: %v", err)
+		}
+		if !bytes.Equal(dec, a) {
+			t.Errorf("got %v, want %v", dec, a)
+		}
+	})
+}
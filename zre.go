@@ -0,0 +1,84 @@
+package cobs
+
+// COBS/ZRE uses the codes up to zreMaxCode like COBS, with zreMaxCode for a
+// group that is not followed by a zero. From zreRunCode onwards a code holds
+// no data bytes and stands for a run of two up to zreMaxRun zeros.
+const (
+	zreMaxCode = 0xd0
+	zreRunCode = 0xd1
+	zreMaxRun  = 0xff - zreRunCode + 2
+)
+
+// zreData returns the number of data bytes following code.
+func zreData(code byte) int {
+	if code >= zreRunCode {
+		return 0
+	}
+
+	return int(code) - 1
+}
+
+// zreZeros returns the number of zeros following the group of code, if
+// another group follows.
+func zreZeros(code byte) int {
+	switch {
+	case code == zreMaxCode:
+		return 0
+	case code >= zreRunCode:
+		return int(code-zreRunCode) + 2
+	default:
+		return 1
+	}
+}
+
+// zreCode returns the code of a run of n zeros.
+func zreCode(n int) byte {
+	if n == 1 {
+		return 1
+	}
+
+	return byte(zreRunCode + n - 2)
+}
+
+// encodeZRE adds c to the current group, collecting zeros that follow a
+// zero in a run.
+func (e *Encoder) encodeZRE(c byte) error {
+	// Finish if group is full
+	if e.buf[0] == zreMaxCode {
+		if err := e.finish(); err != nil {
+			return err
+		}
+	}
+
+	if c == e.split {
+		// A zero ends a group with data, further zeros make a run
+		if len(e.buf) > 1 {
+			return e.finish()
+		}
+
+		if e.run++; e.run < zreMaxRun {
+			return nil
+		}
+
+		return e.endRun()
+	}
+
+	if e.run > 0 {
+		if err := e.endRun(); err != nil {
+			return err
+		}
+	}
+
+	e.buf = append(e.buf, c)
+	e.buf[0]++
+
+	return nil
+}
+
+// endRun writes the code of the current run of zeros.
+func (e *Encoder) endRun() error {
+	e.buf[0] = zreCode(e.run)
+	e.run = 0
+
+	return e.finish()
+}
@@ -0,0 +1,137 @@
+package cobs
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+var zreCases = []struct {
+	name     string
+	dec, enc []byte
+}{
+	{
+		name: "Empty",
+		dec:  []byte{},
+		enc:  []byte{0x01},
+	},
+	{
+		name: "1 zero",
+		dec:  []byte{0x00},
+		enc:  []byte{0xd1},
+	},
+	{
+		name: "3 zeroes",
+		dec:  []byte{0x00, 0x00, 0x00},
+		enc:  []byte{0xd3},
+	},
+	{
+		name: "Single zero",
+		dec:  []byte("ab\x00cd"),
+		enc:  []byte("\x03ab\x03cd"),
+	},
+	{
+		name: "Zero run",
+		dec:  []byte("ab\x00\x00\x00\x00cd"),
+		enc:  []byte("\x03ab\xd2\x03cd"),
+	},
+	{
+		name: "Trailing zeroes",
+		dec:  []byte("a\x00\x00\x00"),
+		enc:  []byte("\x02a\xd2"),
+	},
+	{
+		name: "Longest run",
+		dec:  append(append([]byte{'a'}, make([]byte, 49)...), 'b'),
+		enc:  []byte("\x02a\xff\x02b"),
+	},
+	{
+		name: "Longer run",
+		dec:  append(append([]byte{'a'}, make([]byte, 51)...), 'b'),
+		enc:  []byte("\x02a\xff\xd1\x02b"),
+	},
+	{
+		name: "Full group",
+		dec:  bytes.Repeat([]byte{'a'}, 208),
+		enc:  append(append([]byte{0xd0}, bytes.Repeat([]byte{'a'}, 207)...), 0x02, 'a'),
+	},
+	{
+		name: "Zero after full group",
+		dec:  append(bytes.Repeat([]byte{'a'}, 207), 0x00, 'b'),
+		enc:  append(append([]byte{0xd0}, bytes.Repeat([]byte{'a'}, 207)...), 0x01, 0x02, 'b'),
+	},
+}
+
+func TestZRE(t *testing.T) {
+	for _, tc := range zreCases {
+		t.Run(tc.name, func(t *testing.T) {
+			enc, err := Encode(tc.dec, WithZRE(true))
+			if err != nil {
+				t.Fatalf("encode error: %v", err)
+			}
+			if !bytes.Equal(enc, tc.enc) {
+				t.Errorf("encode got %v, want %v", enc, tc.enc)
+			}
+
+			dec, err := Decode(tc.enc, WithZRE(true))
+			if err != nil {
+				t.Fatalf("decode error: %v", err)
+			}
+			if !bytes.Equal(dec, tc.dec) {
+				t.Errorf("decode got %v, want %v", dec, tc.dec)
+			}
+
+			if err := Validate(tc.enc, WithZRE(true)); err != nil {
+				t.Errorf("validate error: %v", err)
+			}
+
+			if n := MaxEncodedLen(len(tc.dec), WithZRE(true)); len(enc) > n {
+				t.Errorf("got %d bytes, want at most %d", len(enc), n)
+			}
+		})
+	}
+}
+
+func TestZRERoundTrip(t *testing.T) {
+	var data []byte
+	for i := 0; i < 500; i++ {
+		data = append(data, byte(i))
+		data = append(data, make([]byte, i%60)...)
+	}
+
+	for _, opts := range [][]Option{{WithZRE(true)}, {WithZRE(true), WithSentinel(0x42), WithChecksum(CRC8)}} {
+		enc, err := Encode(data, opts...)
+		if err != nil {
+			t.Fatalf("encode error: %v", err)
+		}
+
+		std, _ := Encode(data)
+		if len(enc) >= len(std) {
+			t.Errorf("got %d bytes, want less than %d", len(enc), len(std))
+		}
+
+		var buf bytes.Buffer
+		d := mustDecoder(t, &buf, opts...)
+		if _, err := writeBytes(d, enc); err != nil {
+			t.Fatalf("decode error: %v", err)
+		}
+		if err := d.Close(); err != nil {
+			t.Fatalf("close error: %v", err)
+		}
+		if !bytes.Equal(buf.Bytes(), data) {
+			t.Errorf("round trip differs")
+		}
+	}
+}
+
+func TestZREIncompatible(t *testing.T) {
+	for _, opts := range [][]Option{
+		{WithZRE(true), WithReduced(true)},
+		{WithZRE(true), WithZPE(true)},
+		{WithZRE(true), WithSecondForbidden(0xff)},
+	} {
+		if err := CheckOptions(opts...); !errors.Is(err, ErrIncompatibleOptions) {
+			t.Errorf("got %v, want %v", err, ErrIncompatibleOptions)
+		}
+	}
+}